@@ -0,0 +1,728 @@
+package rununtil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// starter is the form that every kind of runner is converted into, so that
+// they can all share the same await implementation. The context is cancelled
+// as soon as the await has been told to stop. It returns an error if the
+// runner could not be started, in which case the await shuts down the runners
+// which have already started.
+type starter func(ctx context.Context) (stopFunc, error)
+
+// stopFunc is the form that every kind of shutdown function is converted into.
+// The context is done once the shutdown deadline, if there is one, has passed.
+// A starter returns a nil stopFunc if its runner didn't return a shutdown
+// function.
+type stopFunc func(ctx context.Context) error
+
+// asStarter converts the RunnerFunc into a starter whose shutdown never fails.
+func (runner RunnerFunc) asStarter() starter {
+	return func(context.Context) (stopFunc, error) {
+		shutdown := runner()
+		if shutdown == nil {
+			return nil, nil
+		}
+		return func(context.Context) error {
+			shutdown()
+			return nil
+		}, nil
+	}
+}
+
+// asStarter converts the ErrRunnerFunc into a starter.
+func (runner ErrRunnerFunc) asStarter() starter {
+	return func(context.Context) (stopFunc, error) {
+		shutdown := runner()
+		if shutdown == nil {
+			return nil, nil
+		}
+		return func(context.Context) error {
+			return shutdown()
+		}, nil
+	}
+}
+
+// asStarter converts the ContextRunnerFunc into a starter whose shutdown never
+// fails.
+func (runner ContextRunnerFunc) asStarter() starter {
+	return func(ctx context.Context) (stopFunc, error) {
+		shutdown := runner(ctx)
+		if shutdown == nil {
+			return nil, nil
+		}
+		return func(context.Context) error {
+			shutdown()
+			return nil
+		}, nil
+	}
+}
+
+// starters converts all of the runners into components, each of which
+// remembers the runner it was made from.
+func starters[R interface{ asStarter() starter }](runners []R) []component {
+	converted := make([]component, 0, len(runners))
+	for _, runner := range runners {
+		converted = append(converted, component{start: runner.asStarter(), origin: runner})
+	}
+	return converted
+}
+
+// awaitKillSignals runs the provided starters using the default Runner, which
+// is the one that CancelAll cancels.
+func awaitKillSignals(signals []os.Signal, starters []component, opts options) error {
+	return defaultRunner.await(signals, starters, opts)
+}
+
+// await runs the provided starters until either one of the signals has been
+// received or the await has been cancelled, at which point it cancels the
+// context given to the starters, executes all of the shutdown functions and
+// returns their combined errors.
+func (r *Runner) await(signals []os.Signal, starters []component, opts options) error {
+	return r.newSession(signals, opts).run(starters)
+}
+
+// lastKey is the key of the most recent session, which each new session
+// increments so that no two sessions in the process ever share a key.
+var lastKey atomic.Uint64
+
+// session is a single await of a Runner.
+type session struct {
+	runner      *Runner
+	key         string
+	canceller   *canceller
+	opts        options
+	killSignals []os.Signal
+	// caught are the kill signals which were only added by
+	// WithCatchAllFatalSignals.
+	caught []os.Signal
+	// signals receives the signals, either from the operating system via
+	// notified or from the WithSignalSource channel.
+	signals  <-chan os.Signal
+	notified chan os.Signal
+	finish   *cancellation
+	// ctx is given to the starters, and is cancelled once the session has
+	// been told to stop, with cause saying why.
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	cause  error
+	// queued are the components that were added to the Runner before the
+	// session began.
+	queued []component
+	// received is the kill signal which stopped the session, or nil if it
+	// was stopped some other way, and receivedAt is when it was received.
+	received   os.Signal
+	receivedAt time.Time
+	// stoppedBy is the kind of thing which stopped the session, for
+	// AwaitUntil, if it is one of the ReasonKinds.
+	stoppedBy ReasonKind
+	// startErr is why one of the runners failed to start, if that is what
+	// stopped the session.
+	startErr error
+	// profile is the WithSignalProfile of the kill signal which stopped the
+	// session, if it has one.
+	profile *ShutdownProfile
+	// done is closed once the session has finished shutting down, with err
+	// set to the errors of its shutdown.
+	done chan struct{}
+	err  error
+	// stopShutdownSignals stops reading the signals which are received
+	// during the shutdown, once it has finished.
+	stopShutdownSignals func()
+	// stopWatchdog stops the WithHardKillAfter watchdog, and waits for it to
+	// finish, if it was started.
+	stopWatchdog func()
+	// leaderReleased is set once the WithLeaderRelease function has been
+	// called, which is done as soon as the shutdown begins, and leaderErr is
+	// its error.
+	leaderReleased bool
+	leaderErr      error
+	// flushed is set once the WithFinalFlush functions have been called,
+	// which is only ever done once.
+	flushed bool
+
+	mux sync.Mutex
+	// running are the components which have started.
+	running []running
+	// stopping is set once shutdown has begun, after which no more starters
+	// can be added.
+	stopping bool
+	// adding tracks the starters which are being added, or replaced, so
+	// that shutdown can wait for them to finish starting.
+	adding sync.WaitGroup
+	// replacing are the names of the components which are being replaced.
+	replacing map[string]bool
+	// hurry is closed, once, by ForceNow to skip the rest of the session's
+	// delays.
+	hurry     chan struct{}
+	hurryOnce sync.Once
+}
+
+// newSession starts listening for the kill signals, along with any signals
+// that have actions, and registers the session with the Runner's canceller, so
+// that it can be cancelled from then on.
+func (r *Runner) newSession(killSignals []os.Signal, opts options) *session {
+	opts.actions = r.withActions(killSignals, opts.actions)
+	listenAll := len(killSignals) == 0
+	if opts.reExecSignal != nil && !listenAll && !containsSignal(killSignals, opts.reExecSignal) {
+		killSignals = append(append([]os.Signal(nil), killSignals...), opts.reExecSignal)
+	}
+	if len(opts.immediate) > 0 {
+		killSignals, opts.actions = withoutImmediate(killSignals, opts)
+	}
+	if opts.reportJSON != nil && opts.reporter == nil {
+		opts.reporter = &shutdownReporter{}
+	}
+	caught := caughtSignals(killSignals, opts)
+	if len(caught) > 0 {
+		killSignals = append(append([]os.Signal(nil), killSignals...), caught...)
+	}
+	s := &session{
+		runner:      r,
+		key:         strconv.FormatUint(lastKey.Add(1), 10),
+		canceller:   r.canceller,
+		opts:        opts,
+		killSignals: killSignals,
+		caught:      caught,
+		finish:      newCancellation(),
+		done:        make(chan struct{}),
+		hurry:       make(chan struct{}),
+	}
+	s.opts.hurry = s.hurry
+	s.opts.statuses = newStatusTracker(s.opts.metrics)
+	s.ctx, s.cancel = context.WithCancelCause(context.WithValue(context.Background(), runnerKey{}, r))
+	if opts.signalSource != nil {
+		s.signals = opts.signalSource
+	} else {
+		buffer := opts.signalBuffer
+		if buffer < 1 {
+			buffer = 1
+		}
+		s.notified = make(chan os.Signal, buffer)
+		s.signals = s.notified
+		if len(opts.ignored) > 0 {
+			signalIgnore(opts.ignored...)
+		}
+		if listenAll && len(opts.immediate) > 0 {
+			signalNotify(s.notified, allSignalsExcept(opts.immediate)...)
+		} else if listenAll {
+			signalNotify(s.notified)
+		} else {
+			// every one of the kill signals may have been immediate, which
+			// mustn't become listening for every signal
+			if len(killSignals) > 0 {
+				signalNotify(s.notified, killSignals...)
+			}
+			for sig := range opts.actions {
+				signalNotify(s.notified, sig)
+			}
+		}
+	}
+	r.canceller.addChannel(s.key, s.finish)
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	s.queued, r.pending = r.pending, nil
+	r.current = s
+	r.shuttingDown.Store(false)
+	if r.sessions == nil {
+		r.sessions = make(map[*session]struct{})
+	}
+	r.sessions[s] = struct{}{}
+	if r.triggered {
+		r.canceller.cancel(s.key, triggeredReason)
+	}
+	return s
+}
+
+// run runs the starters until the session receives a kill signal or is
+// cancelled, and then stops listening for signals and shuts the starters down.
+func (s *session) run(starters []component) (err error) {
+	opts := s.opts
+	defer func() {
+		s.stopIgnoring()
+		// the ignored signals would stay ignored in the new process
+		if opts.reExecSignal != nil && s.received == opts.reExecSignal {
+			err = errors.Join(err, s.reExec())
+		}
+		s.flush()
+		if s.stopWatchdog != nil {
+			s.stopWatchdog()
+		}
+		s.err = err
+		s.runner.forget(s)
+		close(s.done)
+	}()
+	defer func() {
+		// once shutdown has begun there is nothing left to cancel, but the
+		// signals are still listened for until it has finished, so that a
+		// repeated kill signal doesn't get its default behaviour
+		s.canceller.removeChannel(s.key)
+		if s.stopShutdownSignals == nil {
+			s.stopShutdownSignals = s.listenDuringShutdown()
+		}
+		s.cancel(s.cause)
+		err = errors.Join(err, s.shutdown(s.stop()))
+		opts.metrics.SetPhase(PhaseStopped)
+		s.stopShutdownSignals()
+		s.stopListening()
+	}()
+	opts.metrics.SetPhase(PhaseStarting)
+	all := make([]component, 0, len(starters)+len(s.queued))
+	all = append(all, starters...)
+	all = append(all, startOrder(s.queued)...)
+	if opts.requireRunners && len(all) == 0 {
+		opts.logger.Errorf("no runners were given to await")
+		s.cause = ErrNoRunners
+		return ErrNoRunners
+	}
+	var ctxDone <-chan struct{}
+	if opts.ctx != nil {
+		ctxDone = opts.ctx.Done()
+	}
+	for idx, c := range all {
+		if err := s.start(idx, c); err != nil {
+			// treat the panic, or failure, like a kill signal, shutting
+			// down the runners that have already started
+			s.failed(idx, c, err)
+			s.cause = err
+			s.startErr = err
+			s.beginShutdown()
+			return err
+		}
+	}
+	var ready <-chan struct{}
+	stopProbing := func() {}
+	if opts.readinessProbe != nil {
+		ready, stopProbing = s.probeReadiness()
+	} else {
+		s.declareReady()
+	}
+	startedAt := opts.clock.Now()
+	opts.metrics.SetPhase(PhaseRunning)
+	// the lifetime is how long the runners run for, however long they took
+	// to start
+	var expired <-chan time.Time
+	if opts.maxLifetime > 0 {
+		expired = opts.clock.After(opts.maxLifetime)
+	}
+
+	// Wait for a kill signal, running the actions of any other signals
+	for {
+		select {
+		case <-ready:
+			ready = nil
+			s.declareReady()
+			continue
+		case sig, ok := <-s.signals:
+			if !ok {
+				// the WithSignalSource channel has been closed, so there
+				// won't be any more signals
+				s.signals = nil
+				continue
+			}
+			opts.metrics.IncSignalReceived(sig.String())
+			if !s.isKillSignal(sig) {
+				opts.logger.Infof("received signal %v, running its actions", sig)
+				for _, action := range opts.actions[sig] {
+					action()
+				}
+				continue
+			}
+			if containsSignal(s.caught, sig) {
+				opts.logger.Infof("received signal %v, which isn't one of the kill signals, shutting down anyway", sig)
+			} else {
+				opts.logger.Infof("received signal %v", sig)
+			}
+			s.received = sig
+			s.receivedAt = opts.clock.Now()
+			s.stoppedBy = ReasonSignal
+			s.cause = &SignalError{Signal: sig}
+			s.useProfile(sig)
+			s.waitForMinUptime(startedAt)
+		case <-s.finish.done:
+			s.stoppedBy = ReasonCancelAll
+			s.cause = ErrCancelled
+			if reason := s.finish.reason; reason != "" {
+				opts.logger.Infof("await cancelled: %s", reason)
+				s.cause = fmt.Errorf("%w: %s", ErrCancelled, reason)
+			} else {
+				opts.logger.Infof("await cancelled")
+			}
+		case <-expired:
+			opts.logger.Infof("maximum lifetime of %v reached", opts.maxLifetime)
+			s.stoppedBy = ReasonMaxLifetime
+			s.cause = ErrMaxLifetime
+		case <-opts.done:
+			opts.logger.Infof("done channel closed")
+			s.stoppedBy = ReasonDone
+			s.cause = ErrCancelled
+		case <-ctxDone:
+			s.stoppedBy = ReasonContext
+			s.cause = context.Cause(opts.ctx)
+			opts.logger.Infof("context done: %v", s.cause)
+		}
+		stopProbing()
+		s.beginShutdown()
+		if opts.forceQuit {
+			s.stopShutdownSignals = s.listenDuringShutdown()
+		}
+		s.preShutdown()
+		return nil
+	}
+}
+
+// beginShutdown marks the Runner as shutting down, before anything has been
+// shut down, applies the signal's profile, starts the WithHardKillAfter
+// watchdog, calls the onStopping hooks and releases the leadership.
+func (s *session) beginShutdown() {
+	s.runner.shuttingDown.Store(true)
+	s.applyProfile()
+	s.startWatchdog()
+	s.opts.metrics.IncShutdownStarted()
+	s.opts.metrics.SetPhase(PhaseDraining)
+	for _, stopping := range s.opts.onStopping {
+		stopping(s.opts.logger)
+	}
+	s.releaseLeadership()
+}
+
+// stopListening stops the signals from being delivered to the session.
+func (s *session) stopListening() {
+	if s.notified != nil {
+		signalStop(s.notified)
+		close(s.notified)
+	}
+}
+
+// stopIgnoring restores the default behaviour of the ignored signals. This
+// can't be done with signal.Reset, which leaves ignored signals ignored, but
+// listening for the signals undoes the ignoring and then stopping listening
+// leaves them with their default behaviour.
+func (s *session) stopIgnoring() {
+	if s.notified != nil && len(s.opts.ignored) > 0 {
+		c := make(chan os.Signal, 1)
+		signalNotify(c, s.opts.ignored...)
+		signalStop(c)
+	}
+}
+
+// start launches the component, keeping hold of its shutdown.
+func (s *session) start(idx int, c component) error {
+	started, err := s.launch(idx, c)
+	if err != nil {
+		return err
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.running = append(s.running, started)
+	return nil
+}
+
+// launch runs the component's starter with the session's context, returning
+// it along with its shutdown. A runner which forgot to return its shutdown
+// function is logged, and then treated as having nothing to shut down, rather
+// than panicking during shutdown.
+func (s *session) launch(idx int, c component) (running, error) {
+	shutdown, err := s.startWithin(idx, c)
+	if err != nil {
+		return running{}, err
+	}
+	started := running{component: c, stop: onlyOnce(shutdown)}
+	if shutdown == nil {
+		s.opts.logger.Errorf("%s returned a nil shutdown function, treating it as a no-op", started.describe(idx))
+		started.stop = nopStop
+	}
+	s.opts.statuses.started(idx, c.name, s.opts.clock.Now())
+	return started, nil
+}
+
+// nopStop is the shutdown of a component which has nothing to shut down.
+func nopStop(context.Context) error { return nil }
+
+// onlyOnce wraps the shutdown so that it is executed at most once, however
+// many times it is called, since a ShutdownFunc which, for example, closes a
+// channel would panic if it were executed twice. Every call returns the error
+// of the first, which any later calls wait for.
+func onlyOnce(shutdown stopFunc) stopFunc {
+	if shutdown == nil {
+		return nil
+	}
+	var once sync.Once
+	var err error
+	return func(ctx context.Context) error {
+		once.Do(func() {
+			err = shutdown(ctx)
+		})
+		return err
+	}
+}
+
+// add starts the component while the session is running, so that it is shut
+// down along with the rest. It returns ErrShuttingDown if shutdown has
+// already begun.
+func (s *session) add(c component) error {
+	s.mux.Lock()
+	if s.stopping {
+		s.mux.Unlock()
+		return ErrShuttingDown
+	}
+	s.adding.Add(1)
+	idx := len(s.running)
+	s.mux.Unlock()
+	defer s.adding.Done()
+
+	if err := s.start(idx, c); err != nil {
+		s.failed(idx, c, err)
+		return err
+	}
+	return nil
+}
+
+// failed logs that the runner failed to start, passing it to the panic handler
+// if it panicked.
+func (s *session) failed(idx int, c component, err error) {
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		s.opts.logger.Errorf("%s failed to start: %v", c.describe(idx), err)
+		return
+	}
+	s.opts.logger.Errorf("%s panicked: %v", c.describe(idx), panicErr.Value)
+	if s.opts.panicHandler != nil {
+		s.opts.panicHandler(panicErr.Value)
+	}
+}
+
+// stop stops any more starters from being added, waits for those that are
+// being added to finish starting, and returns all of the components which have
+// started.
+func (s *session) stop() []running {
+	s.mux.Lock()
+	s.stopping = true
+	s.mux.Unlock()
+	s.adding.Wait()
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.running
+}
+
+// shutdown executes the shutdowns, logging how long they took.
+func (s *session) shutdown(shutdowns []running) error {
+	log := s.opts.logger
+	log.Infof("starting shutdown of %d runners", len(shutdowns))
+	start := s.opts.clock.Now()
+	ctx := context.Background()
+	if s.opts.shutdownDeadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.opts.shutdownDeadline)
+		defer cancel()
+	}
+	ctx = context.WithValue(ctx, reasonKey{}, s.reason())
+	ctx, finish := observe(ctx, s.opts.observers, func(observer ShutdownObserver, ctx context.Context) (context.Context, func(error)) {
+		return observer.StartShutdown(ctx, s.received, len(shutdowns))
+	})
+	s.stopChildren(ctx)
+	opts := s.opts
+	stopWarning := func() {}
+	if opts.shutdownWarnAfter > 0 {
+		opts.pending = newPendingShutdowns(shutdowns)
+		stopWarning = warnIfSlow(opts.pending, opts)
+	}
+	// the leadership has normally been released already, by beginShutdown
+	s.releaseLeadership()
+	err := errors.Join(s.leaderErr, shutdownAll(ctx, shutdowns, opts))
+	stopWarning()
+	finish(err)
+	duration := s.opts.clock.Now().Sub(start)
+	s.opts.metrics.ObserveShutdownDuration(duration)
+	if s.opts.reporter != nil {
+		s.opts.reporter.finish(s.received, duration)
+		if s.opts.reportJSON != nil {
+			if err := writeReportJSON(s.opts.reportJSON, s.opts.reporter.report()); err != nil {
+				log.Errorf("failed to write the shutdown report: %v", err)
+			}
+		}
+	}
+	elapsed := duration.Milliseconds()
+	if err != nil {
+		log.Errorf("shutdown completed in %dms with errors: %v", elapsed, err)
+		return err
+	}
+	log.Infof("shutdown complete in %dms", elapsed)
+	return nil
+}
+
+// isKillSignal reports whether the signal should shut the session down. When
+// there are no kill signals every signal is listened for, in the same way as
+// signal.Notify, and so every signal which doesn't have an action, and isn't
+// immediate, is a kill signal.
+func (s *session) isKillSignal(sig os.Signal) bool {
+	if len(s.killSignals) == 0 {
+		_, hasAction := s.opts.actions[sig]
+		return !hasAction && !containsSignal(s.opts.immediate, sig)
+	}
+	return containsSignal(s.killSignals, sig)
+}
+
+// containsSignal reports whether sig is one of the signals.
+func containsSignal(signals []os.Signal, sig os.Signal) bool {
+	for _, s := range signals {
+		if s == sig {
+			return true
+		}
+	}
+	return false
+}
+
+// startSafely runs the starter, recovering from it if it panics in which case
+// it returns a PanicError.
+func startSafely(ctx context.Context, start starter) (shutdown stopFunc, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = newPanicError(recovered)
+		}
+	}()
+	return start(ctx)
+}
+
+// shutdownSafely executes the shutdown, recovering from it if it panics so that
+// the rest of the shutdowns are still executed.
+func shutdownSafely(ctx context.Context, shutdown stopFunc) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = asPanicError(recovered)
+		}
+	}()
+	return shutdown(ctx)
+}
+
+// shutdownAll executes the shutdowns in the configured order, continuing past
+// any failures, and returns all of the errors that occurred joined together.
+// The context is shared by all of the shutdowns.
+func shutdownAll(ctx context.Context, shutdowns []running, opts options) error {
+	if opts.order == OrderParallel {
+		return shutdownConcurrently(ctx, shutdowns, opts)
+	}
+	return shutdownSequentially(ctx, shutdowns, opts)
+}
+
+// shutdownSequentially executes the shutdowns one after the other in reverse
+// order of registration, or in order of registration for OrderForward, except
+// that a component is always shut down after everything which depends on it,
+// each one completing (or timing out) before the next one begins, after the
+// WithShutdownStagger interval.
+func shutdownSequentially(ctx context.Context, shutdowns []running, opts options) error {
+	var errs []error
+	order := dependencyOrder(shutdowns, opts.order == OrderForward)
+	for i, idx := range order {
+		if i > 0 {
+			stagger(ctx, opts)
+		}
+		if err := runObservedShutdown(ctx, idx, shutdowns[idx], opts); err != nil {
+			errs = append(errs, err)
+		}
+		reportProgress(i+1, order, shutdowns, opts)
+	}
+	return errors.Join(errs...)
+}
+
+// shutdownConcurrently executes each of the shutdowns in its own go routine and
+// waits for all of them to complete (or time out). A component's shutdown
+// waits for everything which depends on it to have been shut down first, and a
+// BestEffort one waits for all of the Critical ones.
+func shutdownConcurrently(ctx context.Context, shutdowns []running, opts options) error {
+	errs := make([]error, len(shutdowns))
+	done := make([]chan struct{}, len(shutdowns))
+	for idx := range done {
+		done[idx] = make(chan struct{})
+	}
+	dependents := dependentsOf(shutdowns)
+	for idx, shutdown := range shutdowns {
+		// the runners which are tagged have no name or dependencies, so
+		// waiting for those of a higher priority can't deadlock
+		for other, first := range shutdowns {
+			if first.priority < shutdown.priority {
+				dependents[idx] = append(dependents[idx], other)
+			}
+		}
+	}
+	var wg sync.WaitGroup
+	for idx, shutdown := range shutdowns {
+		wg.Add(1)
+		go func(idx int, shutdown running) {
+			defer wg.Done()
+			defer close(done[idx])
+			for _, dependent := range dependents[idx] {
+				<-done[dependent]
+			}
+			errs[idx] = runObservedShutdown(ctx, idx, shutdown, opts)
+		}(idx, shutdown)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// runObservedShutdown executes the shutdown of the runner with the index, while
+// it is being observed by the observers, and wraps any error it returns with
+// which runner it was.
+func runObservedShutdown(ctx context.Context, idx int, shutdown running, opts options) error {
+	ctx, finish := observe(ctx, opts.observers, func(observer ShutdownObserver, ctx context.Context) (context.Context, func(error)) {
+		return observer.StartRunnerShutdown(ctx, idx)
+	})
+	start := opts.clock.Now()
+	opts.statuses.shuttingDown(idx)
+	err := runShutdown(ctx, shutdown.stop, opts.shutdownTimeout, opts.clock)
+	duration := opts.clock.Now().Sub(start)
+	finish(err)
+	opts.statuses.shutDown(idx, duration)
+	if opts.pending != nil {
+		opts.pending.finished(idx)
+	}
+	if opts.reporter != nil {
+		opts.reporter.addResult(idx, shutdown.label(), duration, err)
+	}
+	if opts.shutdownTiming != nil {
+		opts.shutdownTiming(shutdown.describe(idx), duration, err)
+	}
+	if err != nil {
+		return fmt.Errorf("shutdown of %s: %w", shutdown.describe(idx), err)
+	}
+	return nil
+}
+
+// runShutdown executes the shutdown, giving up on it if it hasn't completed
+// within the timeout, according to the clock, or before the context is done. A
+// zero timeout, with a context that is never done, means wait for as long as
+// it takes.
+func runShutdown(ctx context.Context, shutdown stopFunc, timeout time.Duration, clock Clock) error {
+	if timeout <= 0 && ctx.Done() == nil {
+		return shutdownSafely(ctx, shutdown)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- shutdownSafely(ctx, shutdown)
+	}()
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		expired = clock.After(timeout)
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-expired:
+		return ErrShutdownTimeout
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %w", ErrShutdownDeadline, ctx.Err())
+	}
+}
@@ -0,0 +1,33 @@
+package rununtil
+
+// Awaiter is the interface of a Runner's Await and Cancel, so that code which
+// awaits its runners can be given a fake in its tests, to check which runners
+// it registered without blocking on the kill signals:
+//
+//	func run(awaiter rununtil.Awaiter) error {
+//		return awaiter.Await(dbRunner, workerRunner, httpRunner)
+//	}
+//
+//	func main() {
+//		if err := run(rununtil.Default()); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+type Awaiter interface {
+	// Await runs the RunnerFuncs until a kill signal has been received, or
+	// Cancel has been called, and then executes their graceful shutdown
+	// functions.
+	Await(runnerFuncs ...RunnerFunc) error
+	// Cancel stops the awaits in the same way that a kill signal would.
+	Cancel()
+}
+
+var _ Awaiter = (*Runner)(nil)
+
+// Default returns the Awaiter which is used by the package level functions,
+// such as AwaitKillSignal and CancelAll, so awaiting it is the same as calling
+// AwaitKillSignal except that any PanicError is returned rather than
+// re-panicked.
+func Default() Awaiter {
+	return defaultRunner
+}
@@ -0,0 +1,64 @@
+package rununtil_test
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+// fakeAwaiter is an Awaiter which records the runners it is given to await,
+// rather than running them.
+type fakeAwaiter struct {
+	runners []rununtil.RunnerFunc
+}
+
+func (a *fakeAwaiter) Await(runnerFuncs ...rununtil.RunnerFunc) error {
+	a.runners = append(a.runners, runnerFuncs...)
+	return nil
+}
+
+func (a *fakeAwaiter) Cancel() {}
+
+func TestRununtilAwaiter_Fake(t *testing.T) {
+	var awaiter fakeAwaiter
+	var hasBeenShutdown atomic.Bool
+	run := func(awaiter rununtil.Awaiter) error {
+		runner := helperMakeFakeRunner(&hasBeenShutdown)
+		return awaiter.Await(runner, runner, runner)
+	}
+
+	if err := run(&awaiter); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(awaiter.runners) != 3 {
+		t.Fatalf("expected 3 runners to have been registered, got: %d", len(awaiter.runners))
+	}
+	if hasBeenShutdown.Load() {
+		t.Fatal("expected the fake not to have run the runners")
+	}
+}
+
+func TestRununtilDefault(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	awaiter := rununtil.Default()
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- awaiter.Await(helperMakeFakeRunner(&hasBeenShutdown))
+	}()
+	// the default awaiter is cancelled by CancelAll, like AwaitKillSignal
+	if err := helperKeepCancelling(t, rununtil.CancelAll, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the runner to have been shut down")
+	}
+
+	go func() {
+		errChan <- awaiter.Await(helperMakeFakeRunner(&hasBeenShutdown))
+	}()
+	if err := helperKeepCancelling(t, awaiter.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
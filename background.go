@@ -0,0 +1,63 @@
+package rununtil
+
+import "os"
+
+// AwaitKillSignalInBackground is a nonblocking version of AwaitKillSignal. It
+// starts awaiting a kill signal, SIGINT or SIGTERM, in a go routine and returns
+// a key which can be given to Cancel to stop just this await, leaving any
+// other awaits running. CancelAll stops it too. Use AwaitKillSignalAsync to
+// be able to wait for the shutdown to finish.
+func AwaitKillSignalInBackground(runnerFuncs ...RunnerFunc) string {
+	return defaultRunner.awaitInBackground(defaultSignals(), starters(runnerFuncs), newOptions(nil)).key
+}
+
+// Cancel stops the await started by AwaitKillSignalInBackground which returned
+// the key, in the same way that a kill signal would stop it. Cancelling an
+// await that has already stopped does nothing.
+func Cancel(key string) {
+	defaultRunner.CancelKey(key)
+}
+
+// AwaitKillSignalAsync is the same as AwaitKillSignalInBackground, for
+// embedding rununtil in an app which manages its own main loop, except that
+// instead of a key it returns a channel which is closed once shutdown has
+// finished, along with a function which triggers graceful shutdown of just
+// this await:
+//
+//	done, cancel := rununtil.AwaitKillSignalAsync(runner)
+//	... run the main loop ...
+//	cancel()
+//	<-done
+//
+// CancelAll stops it too. Calling cancel more than once, or after the await
+// has stopped, does nothing.
+func AwaitKillSignalAsync(runnerFuncs ...RunnerFunc) (done <-chan struct{}, cancel func()) {
+	s := defaultRunner.awaitInBackground(defaultSignals(), starters(runnerFuncs), newOptions(nil))
+	return s.done, func() {
+		Cancel(s.key)
+	}
+}
+
+// AwaitInBackground is a nonblocking version of Await. It starts awaiting in a
+// go routine and returns a key which can be given to CancelKey to stop just
+// this await.
+func (r *Runner) AwaitInBackground(runnerFuncs ...RunnerFunc) string {
+	opts := newOptions(r.opts)
+	return r.awaitInBackground(opts.signals, starters(runnerFuncs), opts).key
+}
+
+// CancelKey stops the await started by AwaitInBackground which returned the
+// key, leaving any other awaits of the Runner running.
+func (r *Runner) CancelKey(key string) {
+	r.canceller.cancel(key, "")
+}
+
+// awaitInBackground registers the await before running it in a go routine, so
+// that it can be cancelled by its key as soon as the session is returned.
+func (r *Runner) awaitInBackground(signals []os.Signal, starters []component, opts options) *session {
+	s := r.newSession(signals, opts)
+	go func() {
+		repanic(s.run(starters))
+	}()
+	return s
+}
@@ -0,0 +1,93 @@
+package rununtil_test
+
+import (
+	"testing"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func helperMakeSignallingRunner(shutdown chan<- struct{}) rununtil.RunnerFunc {
+	return rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		return rununtil.ShutdownFunc(func() {
+			close(shutdown)
+		})
+	})
+}
+
+func TestRununtilCancel(t *testing.T) {
+	shutdown1 := make(chan struct{})
+	shutdown2 := make(chan struct{})
+	key1 := rununtil.AwaitKillSignalInBackground(helperMakeSignallingRunner(shutdown1))
+	key2 := rununtil.AwaitKillSignalInBackground(helperMakeSignallingRunner(shutdown2))
+	if key1 == key2 {
+		t.Fatalf("expected unique keys, got: %s", key1)
+	}
+
+	rununtil.Cancel(key1)
+	<-shutdown1
+	select {
+	case <-shutdown2:
+		t.Fatal("expected the second await to still be running")
+	default:
+	}
+
+	rununtil.Cancel(key2)
+	<-shutdown2
+
+	// cancelling an await that has already stopped does nothing
+	rununtil.Cancel(key1)
+}
+
+func TestRunner_CancelKey(t *testing.T) {
+	shutdown1 := make(chan struct{})
+	shutdown2 := make(chan struct{})
+	r := rununtil.New()
+	key1 := r.AwaitInBackground(helperMakeSignallingRunner(shutdown1))
+	r.AwaitInBackground(helperMakeSignallingRunner(shutdown2))
+
+	r.CancelKey(key1)
+	<-shutdown1
+	select {
+	case <-shutdown2:
+		t.Fatal("expected the second await to still be running")
+	default:
+	}
+
+	r.Cancel()
+	<-shutdown2
+}
+
+func TestAwaitKillSignalAsync(t *testing.T) {
+	shutdown1 := make(chan struct{})
+	shutdown2 := make(chan struct{})
+	done1, cancel1 := rununtil.AwaitKillSignalAsync(helperMakeSignallingRunner(shutdown1))
+	done2, cancel2 := rununtil.AwaitKillSignalAsync(helperMakeSignallingRunner(shutdown2))
+
+	cancel1()
+	<-done1
+	select {
+	case <-shutdown1:
+	default:
+		t.Fatal("expected done to be closed after shutdown had finished")
+	}
+	select {
+	case <-done2:
+		t.Fatal("expected the second await to still be running")
+	default:
+	}
+
+	cancel2()
+	<-done2
+
+	// cancelling an await that has already stopped does nothing
+	cancel1()
+}
+
+func TestAwaitKillSignalAsync_CancelAll(t *testing.T) {
+	shutdown := make(chan struct{})
+	done, _ := rununtil.AwaitKillSignalAsync(helperMakeSignallingRunner(shutdown))
+
+	rununtil.CancelAll()
+	<-done
+	<-shutdown
+}
@@ -0,0 +1,45 @@
+package rununtil
+
+// BarrierRunnerFunc is a variant of RunnerFunc which is constructed, e.g. to
+// open its listeners and connections, without starting to do its job until
+// start is closed. start is closed once every BarrierRunnerFunc has been
+// constructed, so that runners which depend on one another being up all
+// begin at the same time, rather than one at a time as they are constructed:
+//
+//	func NewWorker(queue *Queue) rununtil.BarrierRunnerFunc {
+//		return func(start <-chan struct{}) rununtil.ShutdownFunc {
+//			ctx, cancel := context.WithCancel(context.Background())
+//			go func() {
+//				<-start
+//				queue.Consume(ctx)
+//			}()
+//			return cancel
+//		}
+//	}
+type BarrierRunnerFunc func(start <-chan struct{}) ShutdownFunc
+
+// AwaitKillSignalsBarrier runs the provided BarrierRunnerFuncs until it
+// receives a kill signal, SIGINT or SIGTERM, at which point it executes the
+// graceful shutdown functions. The BarrierRunnerFuncs are constructed in
+// order, and start is closed once the last of them has returned its shutdown
+// function. If one of them fails to start, by panicking, then start is never
+// closed, and those which were already constructed are shut down without
+// having been started.
+func AwaitKillSignalsBarrier(runnerFuncs ...BarrierRunnerFunc) {
+	start := make(chan struct{})
+
+	starters := make([]component, 0, len(runnerFuncs)+1)
+	for _, runner := range runnerFuncs {
+		runner := runner
+		wrapped := RunnerFunc(func() ShutdownFunc {
+			return runner(start)
+		}).asStarter()
+		starters = append(starters, component{start: wrapped, origin: runner})
+	}
+	starters = append(starters, component{start: RunnerFunc(func() ShutdownFunc {
+		close(start)
+		return func() {}
+	}).asStarter()})
+
+	repanic(awaitKillSignals(defaultSignals(), starters, newOptions(nil)))
+}
@@ -0,0 +1,75 @@
+package rununtil_test
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRununtilAwaitKillSignalsBarrier(t *testing.T) {
+	var startedEarly atomic.Bool
+	var constructed atomic.Int32
+	started := make(chan struct{}, 2)
+	barrierRunner := rununtil.BarrierRunnerFunc(func(start <-chan struct{}) rununtil.ShutdownFunc {
+		select {
+		case <-start:
+			startedEarly.Store(true)
+		default:
+		}
+		constructed.Add(1)
+		go func() {
+			<-start
+			if constructed.Load() != 2 {
+				startedEarly.Store(true)
+			}
+			started <- struct{}{}
+		}()
+		return func() {}
+	})
+
+	done := make(chan struct{})
+	go func() {
+		rununtil.AwaitKillSignalsBarrier(barrierRunner, barrierRunner)
+		close(done)
+	}()
+	<-started
+	<-started
+	if startedEarly.Load() {
+		t.Fatal("expected the runners not to start until all of them were constructed")
+	}
+
+	helperCancelUntilDone(t, done)
+}
+
+func TestRununtilAwaitKillSignalsBarrier_StartPanics(t *testing.T) {
+	var hasBeenStarted, hasBeenShutdown atomic.Bool
+	barrierRunner := rununtil.BarrierRunnerFunc(func(start <-chan struct{}) rununtil.ShutdownFunc {
+		go func() {
+			<-start
+			hasBeenStarted.Store(true)
+		}()
+		return func() { hasBeenShutdown.Store(true) }
+	})
+	panickingRunner := rununtil.BarrierRunnerFunc(func(<-chan struct{}) rununtil.ShutdownFunc {
+		panic("failed to connect")
+	})
+
+	recovered := make(chan interface{})
+	go func() {
+		defer func() {
+			recovered <- recover()
+		}()
+		rununtil.AwaitKillSignalsBarrier(barrierRunner, panickingRunner)
+	}()
+	value := <-recovered
+	if _, ok := value.(*rununtil.PanicError); !ok {
+		t.Fatalf("expected to re-panic with a PanicError, got: %v", value)
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the constructed runner to have been shutdown")
+	}
+	if hasBeenStarted.Load() {
+		t.Fatal("expected the constructed runner never to have been started")
+	}
+}
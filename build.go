@@ -0,0 +1,37 @@
+package rununtil
+
+import "context"
+
+// RunnerBuilder builds a RunnerFunc, returning an error if it can't, e.g.
+// because of bad config or because a listener couldn't be opened.
+type RunnerBuilder func() (RunnerFunc, error)
+
+// asStarter converts the RunnerBuilder into a starter which fails if the
+// RunnerFunc can't be built.
+func (build RunnerBuilder) asStarter() starter {
+	return func(ctx context.Context) (stopFunc, error) {
+		runner, err := build()
+		if err != nil {
+			return nil, err
+		}
+		return runner.asStarter()(ctx)
+	}
+}
+
+// AwaitKillSignalsBuild builds and starts each of the RunnerFuncs in turn, and
+// then runs them until a kill signal, SIGINT or SIGTERM, has been received, at
+// which point it executes the graceful shutdown functions. If one of the
+// builders fails then the RunnerFuncs which have already started are shut
+// down straight away and it returns the error, instead of leaving the process
+// half started and waiting for a kill signal:
+//
+//	if err := rununtil.AwaitKillSignalsBuild(NewDB(cfg), NewServer(cfg)); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// Otherwise it returns any errors that occurred during shutdown. If a
+// RunnerFunc panics it returns a PanicError, in the same way as a failed
+// builder.
+func AwaitKillSignalsBuild(builders ...RunnerBuilder) error {
+	return awaitKillSignals(defaultSignals(), starters(builders), newOptions(nil))
+}
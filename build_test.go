@@ -0,0 +1,54 @@
+package rununtil_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func helperMakeBuilder(runner rununtil.RunnerFunc, err error) rununtil.RunnerBuilder {
+	return func() (rununtil.RunnerFunc, error) {
+		return runner, err
+	}
+}
+
+func TestRununtilAwaitKillSignalsBuild(t *testing.T) {
+	var hasBeenShutdown1, hasBeenShutdown2 atomic.Bool
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- rununtil.AwaitKillSignalsBuild(
+			helperMakeBuilder(helperMakeFakeRunner(&hasBeenShutdown1), nil),
+			helperMakeBuilder(helperMakeFakeRunner(&hasBeenShutdown2), nil),
+		)
+	}()
+	if err := helperCancelUntilDone(t, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !hasBeenShutdown1.Load() || !hasBeenShutdown2.Load() {
+		t.Fatal("expected the shutdown functions to have been called")
+	}
+}
+
+func TestRununtilAwaitKillSignalsBuild_Fails(t *testing.T) {
+	var hasBeenShutdown1, hasBeenShutdown3 atomic.Bool
+	badConfig := errors.New("bad config")
+
+	// the await must return without being cancelled
+	err := rununtil.AwaitKillSignalsBuild(
+		helperMakeBuilder(helperMakeFakeRunner(&hasBeenShutdown1), nil),
+		helperMakeBuilder(nil, badConfig),
+		helperMakeBuilder(helperMakeFakeRunner(&hasBeenShutdown3), nil),
+	)
+	if !errors.Is(err, badConfig) {
+		t.Fatalf("expected the builder's error, got: %v", err)
+	}
+	if !hasBeenShutdown1.Load() {
+		t.Fatal("expected the runner built before the failure to have been shutdown")
+	}
+	if hasBeenShutdown3.Load() {
+		t.Fatal("expected the runner after the failure to never have been built")
+	}
+}
@@ -0,0 +1,38 @@
+package rununtil
+
+import "os"
+
+// WithCatchAllFatalSignals additionally awaits the common signals which would
+// otherwise kill the process straight away, without executing any of the
+// shutdown functions, such as SIGINT, SIGTERM, SIGHUP and SIGQUIT, and
+// gracefully shuts down after any of them. This protects against an operator
+// using a different signal to the kill signals that were configured. Signals
+// which have actions, such as the reload signals of
+// AwaitKillSignalsWithReload, which are ignored with WithIgnoredSignals, or
+// which are WithImmediateSignals, are left alone. The signal is logged, and is
+// the Signal of the ShutdownReport.
+func WithCatchAllFatalSignals() Option {
+	return func(o *options) {
+		o.catchAllFatal = true
+	}
+}
+
+// caughtSignals returns the fatal signals which aren't already kill signals
+// and which should be caught as if they were.
+func caughtSignals(killSignals []os.Signal, opts options) []os.Signal {
+	if !opts.catchAllFatal || len(killSignals) == 0 {
+		// without any kill signals every signal is already listened for
+		return nil
+	}
+	var caught []os.Signal
+	for _, sig := range fatalSignals() {
+		if _, hasAction := opts.actions[sig]; hasAction {
+			continue
+		}
+		if containsSignal(killSignals, sig) || containsSignal(opts.ignored, sig) || containsSignal(opts.immediate, sig) {
+			continue
+		}
+		caught = append(caught, sig)
+	}
+	return caught
+}
@@ -0,0 +1,83 @@
+package rununtil_test
+
+import (
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRununtilWithCatchAllFatalSignals(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	signals := make(chan os.Signal)
+	type result struct {
+		report rununtil.ShutdownReport
+		err    error
+	}
+	results := make(chan result)
+	go func() {
+		report, err := rununtil.AwaitKillSignalsFull([]rununtil.Option{
+			rununtil.WithSignals(syscall.SIGTERM),
+			rununtil.WithCatchAllFatalSignals(),
+			rununtil.WithSignalSource(signals),
+		}, helperMakeFakeRunner(&hasBeenShutdown))
+		results <- result{report, err}
+	}()
+	signals <- syscall.SIGINT
+
+	res := <-results
+	if res.err != nil {
+		t.Fatalf("unexpected error: %v", res.err)
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the shutdown function to have been called")
+	}
+	if res.report.Signal != syscall.SIGINT {
+		t.Fatalf("expected the report to say SIGINT began the shutdown, got: %v", res.report.Signal)
+	}
+}
+
+func TestRununtilWithCatchAllFatalSignals_Ignored(t *testing.T) {
+	signals := make(chan os.Signal)
+	r := rununtil.New(
+		rununtil.WithSignals(syscall.SIGTERM),
+		rununtil.WithCatchAllFatalSignals(),
+		rununtil.WithIgnoredSignals(syscall.SIGINT),
+		rununtil.WithSignalSource(signals),
+	)
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await()
+	}()
+	signals <- syscall.SIGINT
+	select {
+	case err := <-errChan:
+		t.Fatalf("expected the ignored signal not to have stopped the await, got: %v", err)
+	default:
+	}
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRununtilWithoutCatchAllFatalSignals(t *testing.T) {
+	signals := make(chan os.Signal)
+	r := rununtil.New(rununtil.WithSignals(syscall.SIGTERM), rununtil.WithSignalSource(signals))
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await()
+	}()
+	signals <- syscall.SIGINT
+	select {
+	case err := <-errChan:
+		t.Fatalf("expected only the configured kill signals to stop the await, got: %v", err)
+	default:
+	}
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,30 @@
+package rununtil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrCancelled is the cause of the context given to the runners when the
+// await was cancelled, e.g. by CancelAll, rather than stopped by a signal. If
+// it was cancelled by CancelAllReason the cause wraps ErrCancelled and includes
+// the reason.
+var ErrCancelled = errors.New("await cancelled")
+
+// SignalError is the cause of the context given to the runners when the await
+// was stopped by a kill signal. Runners can find out which signal it was with
+// errors.As:
+//
+//	var sigErr *rununtil.SignalError
+//	if errors.As(context.Cause(ctx), &sigErr) && sigErr.Signal == syscall.SIGTERM {
+//		flush()
+//	}
+type SignalError struct {
+	// Signal is the kill signal which was received.
+	Signal os.Signal
+}
+
+func (e *SignalError) Error() string {
+	return fmt.Sprintf("received signal %v", e.Signal)
+}
@@ -0,0 +1,117 @@
+package rununtil_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+// helperMakeCauseRunner makes a runner which sends the cause of its context
+// when it is shut down.
+func helperMakeCauseRunner(causes chan<- error) rununtil.ContextRunnerFunc {
+	return rununtil.ContextRunnerFunc(func(ctx context.Context) rununtil.ShutdownFunc {
+		return func() {
+			causes <- context.Cause(ctx)
+		}
+	})
+}
+
+func TestRununtilAwaitKillSignalsCtx_SignalCause(t *testing.T) {
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("unexpected error occurred: %v", err)
+	}
+	var sentSignal atomic.Bool
+	causes := make(chan error, 1)
+
+	go helperSendSignal(t, p, &sentSignal, syscall.SIGINT, time.Millisecond)
+	rununtil.AwaitKillSignalsCtx([]os.Signal{syscall.SIGINT}, helperMakeCauseRunner(causes))
+
+	var sigErr *rununtil.SignalError
+	cause := <-causes
+	if !errors.As(cause, &sigErr) {
+		t.Fatalf("expected the cause to be a signal error, got: %v", cause)
+	}
+	if sigErr.Signal != syscall.SIGINT {
+		t.Fatalf("expected the cause to hold SIGINT, got: %v", sigErr.Signal)
+	}
+}
+
+func TestRununtilAwaitKillSignalsCtx_CancelledCause(t *testing.T) {
+	causes := make(chan error, 1)
+
+	done := make(chan struct{})
+	go func() {
+		rununtil.AwaitKillSignalsCtx([]os.Signal{syscall.SIGINT}, helperMakeCauseRunner(causes))
+		close(done)
+	}()
+	helperCancelUntilDone(t, done)
+
+	if cause := <-causes; !errors.Is(cause, rununtil.ErrCancelled) {
+		t.Fatalf("expected the cause to be ErrCancelled, got: %v", cause)
+	}
+}
+
+func TestRununtilAwaitKillSignalsCtx_PanicCause(t *testing.T) {
+	causes := make(chan error, 1)
+	panicking := rununtil.ContextRunnerFunc(func(ctx context.Context) rununtil.ShutdownFunc {
+		panic("address already in use")
+	})
+
+	func() {
+		defer func() {
+			recover()
+		}()
+		rununtil.AwaitKillSignalsCtx([]os.Signal{syscall.SIGINT}, helperMakeCauseRunner(causes), panicking)
+	}()
+
+	var panicErr *rununtil.PanicError
+	cause := <-causes
+	if !errors.As(cause, &panicErr) {
+		t.Fatalf("expected the cause to be a panic error, got: %v", cause)
+	}
+	if panicErr.Value != "address already in use" {
+		t.Fatalf("expected the cause to hold the panic value, got: %v", panicErr.Value)
+	}
+}
+
+func TestRununtilCancelAllReason(t *testing.T) {
+	causes := make(chan error, 1)
+
+	done := make(chan struct{})
+	go func() {
+		rununtil.AwaitKillSignalsCtx([]os.Signal{syscall.SIGINT}, helperMakeCauseRunner(causes))
+		close(done)
+	}()
+	helperKeepCancelling(t, func() { rununtil.CancelAllReason("config reloaded") }, done)
+
+	cause := <-causes
+	if !errors.Is(cause, rununtil.ErrCancelled) {
+		t.Fatalf("expected the cause to wrap ErrCancelled, got: %v", cause)
+	}
+	if !strings.Contains(cause.Error(), "config reloaded") {
+		t.Fatalf("expected the cause to include the reason, got: %v", cause)
+	}
+}
+
+func TestRunner_CancelReason(t *testing.T) {
+	logger := &fakeLogger{}
+	r := rununtil.New(rununtil.WithLogger(logger))
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await()
+	}()
+	helperKeepCancelling(t, func() { r.CancelReason("test finished") }, errChan)
+
+	if !logger.contains("INFO: await cancelled: test finished") {
+		t.Fatalf("expected the reason to have been logged, got: %v", logger.lines)
+	}
+}
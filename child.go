@@ -0,0 +1,70 @@
+package rununtil
+
+import "context"
+
+// NewChild creates a Runner, configured with the same options as r, which is
+// part of r's tree of Runners, so that a process made up of modules can give
+// each of them its own Runner and still shut them down as one:
+//
+//	worker := r.NewChild()
+//	go worker.Await(queueRunner)
+//	err := r.Await(httpRunner)
+//
+// Cancelling r, with Cancel, CancelReason or Stop, cancels the awaits of all
+// of its descendants as well, and Stop waits for them to finish shutting down.
+// However one of r's awaits is stopped, it cancels the awaits of the children
+// and waits for them to finish shutting down, within the shutdown deadline,
+// before it executes its own shutdown functions, since the children may rely
+// on what r runs. Cancelling a child only affects the child and its own
+// descendants: r keeps running, and it still cancels the child's later awaits.
+func (r *Runner) NewChild() *Runner {
+	child := New(r.opts...)
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.children = append(r.children, child)
+	return child
+}
+
+// childRunners returns the Runners created with NewChild.
+func (r *Runner) childRunners() []*Runner {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return append([]*Runner(nil), r.children...)
+}
+
+// awaiting returns the awaits of the Runner, and of its descendants, which
+// haven't finished shutting down.
+func (r *Runner) awaiting() []*session {
+	r.mux.Lock()
+	sessions := make([]*session, 0, len(r.sessions))
+	for s := range r.sessions {
+		sessions = append(sessions, s)
+	}
+	r.mux.Unlock()
+	for _, child := range r.childRunners() {
+		sessions = append(sessions, child.awaiting()...)
+	}
+	return sessions
+}
+
+// stopChildren cancels the awaits of the Runner's children and waits for them
+// to finish shutting down, or for the context to be done.
+func (s *session) stopChildren(ctx context.Context) {
+	var sessions []*session
+	for _, child := range s.runner.childRunners() {
+		sessions = append(sessions, child.awaiting()...)
+		child.Cancel()
+	}
+	if len(sessions) == 0 {
+		return
+	}
+	s.opts.logger.Infof("waiting for %d child awaits to shut down", len(sessions))
+	for _, child := range sessions {
+		select {
+		case <-child.done:
+		case <-ctx.Done():
+			s.opts.logger.Errorf("gave up waiting for the child awaits to shut down: %v", ctx.Err())
+			return
+		}
+	}
+}
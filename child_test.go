@@ -0,0 +1,113 @@
+package rununtil_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+// helperAwaitTree starts an await on each of the Runners, with a runner
+// recorded under the Runner's name, and waits for all of them to be awaiting.
+func helperAwaitTree(t *testing.T, rec *orderRecorder, runners map[string]*rununtil.Runner) map[string]chan error {
+	t.Helper()
+	errChans := make(map[string]chan error, len(runners))
+	for name, r := range runners {
+		name, r := name, r
+		errChan := make(chan error, 1)
+		errChans[name] = errChan
+		go func() {
+			errChan <- r.Await(rec.runner(name))
+		}()
+		if !helperWaitFor(func() bool { return r.NumAwaiting() > 0 }) {
+			t.Fatalf("expected %s to be awaiting", name)
+		}
+	}
+	return errChans
+}
+
+func TestRunner_NewChild(t *testing.T) {
+	rec := &orderRecorder{}
+	parent := rununtil.New()
+	child := parent.NewChild()
+	grandchild := child.NewChild()
+	errChans := helperAwaitTree(t, rec, map[string]*rununtil.Runner{
+		"parent":     parent,
+		"child":      child,
+		"grandchild": grandchild,
+	})
+
+	parent.Cancel()
+	for name, errChan := range errChans {
+		if err := <-errChan; err != nil {
+			t.Fatalf("unexpected error from %s: %v", name, err)
+		}
+	}
+	// each Runner waits for its children before shutting down its own
+	// runners
+	helperAssertBefore(t, rec.shutdown, "grandchild", "child")
+	helperAssertBefore(t, rec.shutdown, "child", "parent")
+}
+
+func TestRunner_NewChild_CancelledIndependently(t *testing.T) {
+	rec := &orderRecorder{}
+	parent := rununtil.New()
+	child := parent.NewChild()
+	grandchild := child.NewChild()
+	sibling := parent.NewChild()
+	errChans := helperAwaitTree(t, rec, map[string]*rununtil.Runner{
+		"parent":     parent,
+		"child":      child,
+		"grandchild": grandchild,
+		"sibling":    sibling,
+	})
+
+	child.Cancel()
+	for _, name := range []string{"child", "grandchild"} {
+		if err := <-errChans[name]; err != nil {
+			t.Fatalf("unexpected error from %s: %v", name, err)
+		}
+	}
+	if parent.NumAwaiting() != 1 || sibling.NumAwaiting() != 1 {
+		t.Fatal("expected cancelling the child to leave its parent and sibling running")
+	}
+
+	// the child is still part of the tree, so its next await is cancelled
+	// along with the parent
+	childErr := make(chan error, 1)
+	go func() {
+		childErr <- child.Await(rec.runner("child again"))
+	}()
+	if !helperWaitFor(func() bool { return child.NumAwaiting() > 0 }) {
+		t.Fatal("expected the child to be awaiting again")
+	}
+	parent.Cancel()
+	for _, errChan := range []chan error{errChans["parent"], errChans["sibling"], childErr} {
+		if err := <-errChan; err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestRunner_NewChild_Stop(t *testing.T) {
+	rec := &orderRecorder{}
+	parent := rununtil.New()
+	child := parent.NewChild()
+	grandchild := child.NewChild()
+	helperAwaitTree(t, rec, map[string]*rununtil.Runner{
+		"child":      child,
+		"grandchild": grandchild,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := parent.Stop(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rec.mux.Lock()
+	defer rec.mux.Unlock()
+	if len(rec.shutdown) != 2 {
+		t.Fatalf("expected Stop to wait for every descendant to shut down, got: %v", rec.shutdown)
+	}
+}
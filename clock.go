@@ -0,0 +1,30 @@
+package rununtil
+
+import "time"
+
+// Clock tells the time for an await, so that tests can control how long the
+// shutdown timeouts and the lame duck delay take rather than waiting for them
+// in real time.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After returns a channel which receives the time once the duration has
+	// passed.
+	After(d time.Duration) <-chan time.Time
+}
+
+// WithClock sets the Clock which the await uses for the WithShutdownTimeout
+// timeouts, the WithLameDuckDelay delay and the durations that it logs and
+// records. The WithShutdownDeadline deadline is always in real time, since it
+// is a context.Context deadline. By default the real time is used.
+func WithClock(clock Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// realClock is the default Clock, which tells the real time.
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
@@ -0,0 +1,99 @@
+package rununtil_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+// fakeClock is a Clock whose time only moves on when the test advances it.
+type fakeClock struct {
+	mux     sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+	waiting chan struct{}
+}
+
+type fakeWaiter struct {
+	at time.Time
+	c  chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(0, 0), waiting: make(chan struct{}, 100)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	ch := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, fakeWaiter{at: c.now.Add(d), c: ch})
+	c.waiting <- struct{}{}
+	return ch
+}
+
+// advance moves the time on, firing all of the channels that are due.
+func (c *fakeClock) advance(d time.Duration) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.now = c.now.Add(d)
+	remaining := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			remaining = append(remaining, w)
+			continue
+		}
+		w.c <- c.now
+	}
+	c.waiters = remaining
+}
+
+func TestRununtilWithClock_ShutdownTimeout(t *testing.T) {
+	clock := newFakeClock()
+	hang := make(chan struct{})
+	defer close(hang)
+	r := rununtil.New(rununtil.WithClock(clock), rununtil.WithShutdownTimeout(time.Hour))
+	hangingRunner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		return func() { <-hang }
+	})
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(hangingRunner)
+	}()
+	helperKeepCancelling(t, r.Cancel, clock.waiting)
+
+	select {
+	case err := <-errChan:
+		t.Fatalf("expected the await to still be waiting for the shutdown function, got: %v", err)
+	default:
+	}
+	clock.advance(time.Hour)
+	if err := <-errChan; !errors.Is(err, rununtil.ErrShutdownTimeout) {
+		t.Fatalf("expected a shutdown timeout error, got: %v", err)
+	}
+}
+
+func TestRununtilWithClock_LameDuckDelay(t *testing.T) {
+	clock := newFakeClock()
+	r := rununtil.New(rununtil.WithClock(clock), rununtil.WithLameDuckDelay(time.Minute))
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await()
+	}()
+	helperKeepCancelling(t, r.Cancel, clock.waiting)
+	clock.advance(time.Minute)
+	if err := <-errChan; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package rununtil
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// ContextCloser is a resource which is closed with a context, such as an
+// OpenTelemetry exporter or a pgxpool.Pool.
+type ContextCloser interface {
+	Close(ctx context.Context) error
+}
+
+// CloserRunner returns a RunnerFunc which has nothing to start, and whose
+// ShutdownFunc closes c, so that a resource which only needs closing doesn't
+// need a closure of its own. With Compose it declares everything that is
+// closed on shutdown in one place:
+//
+//	rununtil.AwaitKillSignal(
+//		rununtil.Compose(rununtil.CloserRunner(db), rununtil.CloserRunner(logFile)),
+//		NewServerRunner(db),
+//	)
+//
+// An error from Close is logged with the Logger set by SetLogger, see
+// CloserRunnerE to have it returned by the await instead.
+func CloserRunner(c io.Closer) RunnerFunc {
+	return logCloseError(CloserRunnerE(c))
+}
+
+// CloserRunnerCtx is the same as CloserRunner, for a resource which is closed
+// with a context. The context is never done, so use WithShutdownTimeout, or
+// the resource's own timeouts, if Close could hang.
+func CloserRunnerCtx(c ContextCloser) RunnerFunc {
+	return logCloseError(CloserRunnerCtxE(c))
+}
+
+// CloserRunnerE is the same as CloserRunner, except that it returns an
+// ErrRunnerFunc whose ShutdownErrFunc returns the error from Close, for
+// AwaitKillSignalE, so that it is joined into the errors of the await.
+func CloserRunnerE(c io.Closer) ErrRunnerFunc {
+	return ErrRunnerFunc(func() ShutdownErrFunc {
+		return func() error {
+			if err := c.Close(); err != nil {
+				return fmt.Errorf("failed to close %T: %w", c, err)
+			}
+			return nil
+		}
+	})
+}
+
+// CloserRunnerCtxE is the same as CloserRunnerCtx, except that the error from
+// Close is returned in the same way as CloserRunnerE.
+func CloserRunnerCtxE(c ContextCloser) ErrRunnerFunc {
+	return ErrRunnerFunc(func() ShutdownErrFunc {
+		return func() error {
+			if err := c.Close(context.Background()); err != nil {
+				return fmt.Errorf("failed to close %T: %w", c, err)
+			}
+			return nil
+		}
+	})
+}
+
+// logCloseError converts the closer's ErrRunnerFunc into a RunnerFunc which
+// logs the error with the Logger set by SetLogger.
+func logCloseError(runner ErrRunnerFunc) RunnerFunc {
+	return RunnerFunc(func() ShutdownFunc {
+		shutdown := runner()
+		return func() {
+			if err := shutdown(); err != nil {
+				getDefaultLogger().Errorf("%v", err)
+			}
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package rununtil_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+type fakeCloser struct {
+	closed bool
+	err    error
+}
+
+func (c *fakeCloser) Close() error {
+	c.closed = true
+	return c.err
+}
+
+type fakeContextCloser struct {
+	ctx context.Context
+}
+
+func (c *fakeContextCloser) Close(ctx context.Context) error {
+	c.ctx = ctx
+	return nil
+}
+
+func TestCloserRunner(t *testing.T) {
+	closer := &fakeCloser{}
+	shutdown := rununtil.CloserRunner(closer)()
+	if closer.closed {
+		t.Fatal("expected the closer not to be closed when started")
+	}
+	shutdown()
+	if !closer.closed {
+		t.Fatal("expected the closer to have been closed on shutdown")
+	}
+}
+
+func TestCloserRunner_Error(t *testing.T) {
+	logger := &fakeLogger{}
+	rununtil.SetLogger(logger)
+	defer rununtil.SetLogger(nil)
+	closer := &fakeCloser{err: errors.New("connection reset")}
+
+	rununtil.CloserRunner(closer)()()
+
+	if !logger.contains("failed to close *rununtil_test.fakeCloser: connection reset") {
+		t.Fatalf("expected the error to have been logged, got: %v", logger.lines)
+	}
+}
+
+func TestCloserRunner_Compose(t *testing.T) {
+	db, logFile := &fakeCloser{}, &fakeCloser{}
+	r := rununtil.New()
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(rununtil.Compose(rununtil.CloserRunner(db), rununtil.CloserRunner(logFile)))
+	}()
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !db.closed || !logFile.closed {
+		t.Fatal("expected both of the composed closers to have been closed on shutdown")
+	}
+}
+
+func TestCloserRunnerCtx(t *testing.T) {
+	closer := &fakeContextCloser{}
+	shutdown := rununtil.CloserRunnerCtx(closer)()
+	if closer.ctx != nil {
+		t.Fatal("expected the closer not to be closed when started")
+	}
+	shutdown()
+	if closer.ctx == nil {
+		t.Fatal("expected the closer to have been closed on shutdown")
+	}
+}
+
+func TestCloserRunnerE(t *testing.T) {
+	closeErr := errors.New("connection reset")
+	closer := &fakeCloser{err: closeErr}
+
+	err := rununtil.CloserRunnerE(closer)()()
+
+	if !errors.Is(err, closeErr) {
+		t.Fatalf("expected the close error to have been returned, got: %v", err)
+	}
+	if err.Error() != "failed to close *rununtil_test.fakeCloser: connection reset" {
+		t.Fatalf("expected the error to say what failed to close, got: %v", err)
+	}
+}
+
+func TestCloserRunnerE_ErrorReturnedByAwait(t *testing.T) {
+	closeErr := errors.New("connection reset")
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- rununtil.AwaitKillSignalsE([]os.Signal{syscall.SIGINT}, rununtil.CloserRunnerE(&fakeCloser{err: closeErr}))
+	}()
+	if err := helperCancelUntilDone(t, errChan); !errors.Is(err, closeErr) {
+		t.Fatalf("expected the close error to have been returned by the await, got: %v", err)
+	}
+}
+
+func TestCloserRunnerCtxE(t *testing.T) {
+	closer := &fakeContextCloser{}
+	if err := rununtil.CloserRunnerCtxE(closer)()(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if closer.ctx == nil {
+		t.Fatal("expected the closer to have been closed on shutdown")
+	}
+}
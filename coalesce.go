@@ -0,0 +1,34 @@
+package rununtil
+
+import (
+	"os"
+	"time"
+)
+
+// WithSignalCoalescing treats repeats of the kill signal which began the
+// shutdown, received within window of it, as part of the same signal, e.g. an
+// orchestrator retrying its SIGTERM. The graceful shutdown is only ever run
+// once, but without coalescing such a repeat cuts short the WithMinUptime wait
+// and the WithLameDuckDelay delay, and with WithForceQuitOnSecondSignal exits
+// the process. A repeat received after the window, or a different kill signal,
+// still does. A window of zero, the default, doesn't coalesce anything.
+func WithSignalCoalescing(window time.Duration) Option {
+	return func(o *options) {
+		o.coalesceWindow = window
+	}
+}
+
+// coalesced reports whether sig repeats the kill signal which began the
+// shutdown within the WithSignalCoalescing window, logging that it is being
+// ignored if so.
+func (s *session) coalesced(sig os.Signal) bool {
+	window := s.opts.coalesceWindow
+	if window <= 0 || s.received == nil || sig != s.received {
+		return false
+	}
+	if s.opts.clock.Now().Sub(s.receivedAt) > window {
+		return false
+	}
+	s.opts.logger.Infof("received signal %v again within %v, ignoring it", sig, window)
+	return true
+}
@@ -0,0 +1,142 @@
+package rununtil_test
+
+import (
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+// helperSignalBurst sends a kill signal to start shutdown, then advances the
+// clock by gap and sends a burst of the same signal while the runner is being
+// shut down, returning how many times its shutdown function was executed.
+func helperSignalBurst(t *testing.T, gap time.Duration, opts ...rununtil.Option) int64 {
+	t.Helper()
+	clock := newFakeClock()
+	signals := make(chan os.Signal)
+	shuttingDown := make(chan struct{})
+	release := make(chan struct{})
+	var shutdowns atomic.Int64
+	opts = append(opts, rununtil.WithClock(clock), rununtil.WithSignalSource(signals), rununtil.WithForceQuitOnSecondSignal())
+	r := rununtil.New(opts...)
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(func() rununtil.ShutdownFunc {
+			return func() {
+				if shutdowns.Add(1) == 1 {
+					close(shuttingDown)
+				}
+				<-release
+			}
+		})
+	}()
+	signals <- syscall.SIGTERM
+	<-shuttingDown
+	clock.advance(gap)
+	for i := 0; i < 5; i++ {
+		signals <- syscall.SIGTERM
+	}
+	close(release)
+	if err := <-errChan; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return shutdowns.Load()
+}
+
+func TestRununtilWithSignalCoalescing(t *testing.T) {
+	codes := helperCaptureExit(t)
+	logger := &fakeLogger{}
+	shutdowns := helperSignalBurst(t, time.Second, rununtil.WithSignalCoalescing(time.Minute), rununtil.WithLogger(logger))
+
+	if shutdowns != 1 {
+		t.Fatalf("expected the shutdown to have run once, got: %d", shutdowns)
+	}
+	select {
+	case code := <-codes:
+		t.Fatalf("expected the repeated signals not to force quit, got exit code: %d", code)
+	default:
+	}
+	if !logger.contains("INFO: received signal terminated again within 1m0s, ignoring it") {
+		t.Fatalf("expected the repeated signals to have been logged, got: %v", logger.lines)
+	}
+}
+
+func TestRununtilWithSignalCoalescing_AfterWindow(t *testing.T) {
+	codes := make(chan int, 5)
+	shutdowns := helperSignalBurst(t, 2*time.Minute, rununtil.WithSignalCoalescing(time.Minute), rununtil.WithExitFunc(func(code int) {
+		codes <- code
+	}))
+
+	if shutdowns != 1 {
+		t.Fatalf("expected the shutdown to have run once, got: %d", shutdowns)
+	}
+	if code := <-codes; code != 130 {
+		t.Fatalf("expected a repeat after the window to force quit with 130, got: %d", code)
+	}
+}
+
+func TestRununtilSignalBurst(t *testing.T) {
+	var shutdowns atomic.Int64
+	signals := make(chan os.Signal, 5)
+	for i := 0; i < 5; i++ {
+		signals <- syscall.SIGTERM
+	}
+	r := rununtil.New(rununtil.WithSignalSource(signals))
+	if err := r.Await(func() rununtil.ShutdownFunc {
+		return func() { shutdowns.Add(1) }
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := shutdowns.Load(); n != 1 {
+		t.Fatalf("expected a burst of signals to run the shutdown once, got: %d", n)
+	}
+}
+
+func TestRununtilWithSignalCoalescing_WithoutForceQuit(t *testing.T) {
+	notified := make(chan chan<- os.Signal, 1)
+	defer rununtil.SetSignalNotify(func(c chan<- os.Signal, sig ...os.Signal) {
+		notified <- c
+	})()
+	var stopped atomic.Bool
+	defer rununtil.SetSignalStop(func(c chan<- os.Signal) {
+		stopped.Store(true)
+	})()
+	logger := &fakeLogger{}
+	r := rununtil.New(rununtil.WithLogger(logger), rununtil.WithSignalCoalescing(time.Minute))
+	shuttingDown := make(chan struct{})
+	release := make(chan struct{})
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(func() rununtil.ShutdownFunc {
+			return func() {
+				close(shuttingDown)
+				<-release
+			}
+		})
+	}()
+	signals := <-notified
+	signals <- syscall.SIGTERM
+	<-shuttingDown
+	// the repeats are still read, rather than left to kill the process
+	for i := 0; i < 5; i++ {
+		signals <- syscall.SIGTERM
+	}
+	if stopped.Load() {
+		t.Fatal("expected to still be listening for signals while shutting down")
+	}
+	close(release)
+	if err := <-errChan; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !stopped.Load() {
+		t.Fatal("expected to have stopped listening once the shutdown had finished")
+	}
+	if !logger.contains("INFO: received signal terminated again within 1m0s, ignoring it") {
+		t.Fatalf("expected the repeated signals to have been ignored, got: %v", logger.lines)
+	}
+}
@@ -0,0 +1,70 @@
+package rununtil
+
+// CombineShutdown returns a single ShutdownFunc which executes each of the
+// funcs in the reverse order to which they were provided, in the same way that
+// the shutdown functions of several RunnerFuncs would be executed. This keeps
+// a RunnerFunc which owns several resources readable:
+//
+//	return rununtil.CombineShutdown(closeFile, closePool, shutdownServer)
+//
+// Any nil funcs are skipped. If one of the funcs panics the rest are still
+// executed, and then the returned ShutdownFunc re-panics with a PanicError for
+// the first panic.
+func CombineShutdown(funcs ...ShutdownFunc) ShutdownFunc {
+	return ShutdownFunc(func() {
+		var first *PanicError
+		for i := len(funcs) - 1; i >= 0; i-- {
+			if funcs[i] == nil {
+				continue
+			}
+			if panicErr := callSafely(funcs[i]); panicErr != nil && first == nil {
+				first = panicErr
+			}
+		}
+		if first != nil {
+			panic(first)
+		}
+	})
+}
+
+// callSafely executes fn, returning a PanicError if it panics, or the
+// PanicError it panicked with, so that a nested CombineShutdown isn't wrapped
+// twice.
+func callSafely(fn func()) (panicErr *PanicError) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			panicErr = asPanicError(recovered)
+		}
+	}()
+	fn()
+	return nil
+}
+
+// Compose returns a single RunnerFunc which starts each of the runners in the
+// order they were provided, and whose ShutdownFunc shuts them down in the
+// reverse order with CombineShutdown. This lets a library export a whole
+// subsystem as one runner:
+//
+//	func Observability(cfg Config) rununtil.RunnerFunc {
+//		return rununtil.Compose(metricsServer(cfg), traceExporter(cfg), logFlusher(cfg))
+//	}
+//
+// If one of the runners panics while starting, those which have already
+// started are shut down before the panic is passed on, so that the composed
+// runner fails to start as a whole.
+func Compose(runners ...RunnerFunc) RunnerFunc {
+	return RunnerFunc(func() ShutdownFunc {
+		shutdowns := make([]ShutdownFunc, 0, len(runners))
+		started := false
+		defer func() {
+			if !started {
+				_ = callSafely(CombineShutdown(shutdowns...))
+			}
+		}()
+		for _, runner := range runners {
+			shutdowns = append(shutdowns, runner())
+		}
+		started = true
+		return CombineShutdown(shutdowns...)
+	})
+}
@@ -0,0 +1,128 @@
+package rununtil_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestCombineShutdown(t *testing.T) {
+	var order []int
+	record := func(i int) rununtil.ShutdownFunc {
+		return func() {
+			order = append(order, i)
+		}
+	}
+
+	rununtil.CombineShutdown(record(1), record(2), record(3))()
+
+	if expected := []int{3, 2, 1}; !reflect.DeepEqual(order, expected) {
+		t.Fatalf("expected shutdown order %v, got: %v", expected, order)
+	}
+}
+
+func TestCombineShutdown_Panics(t *testing.T) {
+	var order []int
+	record := func(i int) rununtil.ShutdownFunc {
+		return func() {
+			order = append(order, i)
+		}
+	}
+	panicking := func(value interface{}) rununtil.ShutdownFunc {
+		return func() {
+			panic(value)
+		}
+	}
+
+	r := rununtil.New()
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(func() rununtil.ShutdownFunc {
+			return rununtil.CombineShutdown(record(1), panicking("first"), record(2), panicking("second"), record(3))
+		})
+	}()
+	err := helperKeepCancelling(t, r.Cancel, errChan)
+
+	if expected := []int{3, 2, 1}; !reflect.DeepEqual(order, expected) {
+		t.Fatalf("expected every func to have been executed in order %v, got: %v", expected, order)
+	}
+	var panicErr *rununtil.PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("expected a panic error, got: %v", err)
+	}
+	if panicErr.Value != "second" {
+		t.Fatalf("expected the first panic to be reported, got: %v", panicErr.Value)
+	}
+}
+
+func TestCombineShutdown_NestedPanics(t *testing.T) {
+	panicking := rununtil.ShutdownFunc(func() {
+		panic("boom")
+	})
+
+	r := rununtil.New()
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(func() rununtil.ShutdownFunc {
+			return rununtil.CombineShutdown(rununtil.CombineShutdown(panicking))
+		})
+	}()
+	err := helperKeepCancelling(t, r.Cancel, errChan)
+
+	var panicErr *rununtil.PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("expected a panic error, got: %v", err)
+	}
+	if panicErr.Value != "boom" {
+		t.Fatalf("expected the original panic not to have been wrapped again, got: %v", panicErr.Value)
+	}
+}
+
+func TestCompose(t *testing.T) {
+	var order []string
+	record := func(i int) rununtil.RunnerFunc {
+		return func() rununtil.ShutdownFunc {
+			order = append(order, fmt.Sprintf("start %d", i))
+			return func() {
+				order = append(order, fmt.Sprintf("shutdown %d", i))
+			}
+		}
+	}
+
+	shutdown := rununtil.Compose(record(1), record(2), record(3))()
+	if expected := []string{"start 1", "start 2", "start 3"}; !reflect.DeepEqual(order, expected) {
+		t.Fatalf("expected start order %v, got: %v", expected, order)
+	}
+	order = nil
+	shutdown()
+	if expected := []string{"shutdown 3", "shutdown 2", "shutdown 1"}; !reflect.DeepEqual(order, expected) {
+		t.Fatalf("expected shutdown order %v, got: %v", expected, order)
+	}
+}
+
+func TestCompose_StartPanics(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	var hasBeenStarted atomic.Bool
+	laterRunner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		hasBeenStarted.Store(true)
+		return func() {}
+	})
+
+	r := rununtil.New()
+	err := r.Await(rununtil.Compose(helperMakeFakeRunner(&hasBeenShutdown), helperMakePanickingRunner("failed to connect"), laterRunner))
+
+	var panicErr *rununtil.PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("expected a PanicError, got: %v", err)
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the runner which had already started to have been shutdown")
+	}
+	if hasBeenStarted.Load() {
+		t.Fatal("expected the runner after the panic not to have been started")
+	}
+}
@@ -0,0 +1,45 @@
+package rununtil
+
+import (
+	"context"
+	"os"
+)
+
+// ContextRunnerFunc is a variant of RunnerFunc which is given a context that
+// is cancelled as soon as a kill signal has been received (or CancelAll has
+// been called), before any of the ShutdownFuncs are executed. context.Cause
+// reports why it was cancelled: a *SignalError for a kill signal, ErrCancelled
+// if the await was cancelled, ErrMaxLifetime once the WithMaxLifetime has
+// passed, or the error of a runner which failed to start, such as a
+// PanicError. The context also carries the Runner, see RunnerFromContext. Long
+// running workers can simply return when the context is done:
+//
+//	func(ctx context.Context) rununtil.ShutdownFunc {
+//		go func() {
+//			for {
+//				select {
+//				case <-ctx.Done():
+//					return
+//				case <-ticker.C:
+//					poll()
+//				}
+//			}
+//		}()
+//		return func() {}
+//	}
+type ContextRunnerFunc func(ctx context.Context) ShutdownFunc
+
+// AwaitKillSignalCtx runs the provided ContextRunnerFuncs until it receives a
+// kill signal, SIGINT or SIGTERM, at which point it cancels their context and
+// then executes the graceful shutdown functions.
+func AwaitKillSignalCtx(runnerFuncs ...ContextRunnerFunc) {
+	AwaitKillSignalsCtx(defaultSignals(), runnerFuncs...)
+}
+
+// AwaitKillSignalsCtx runs the provided ContextRunnerFuncs until the specified
+// signals have been received, at which point it cancels their context and then
+// executes the graceful shutdown functions. All of the ContextRunnerFuncs share
+// the same context, which is cancelled exactly once.
+func AwaitKillSignalsCtx(signals []os.Signal, runnerFuncs ...ContextRunnerFunc) {
+	repanic(awaitKillSignals(signals, starters(runnerFuncs), newOptions(nil)))
+}
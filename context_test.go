@@ -0,0 +1,62 @@
+package rununtil_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRununtilAwaitKillSignalCtx(t *testing.T) {
+	var contexts []context.Context
+	var cancelledBeforeShutdown []bool
+	runner := rununtil.ContextRunnerFunc(func(ctx context.Context) rununtil.ShutdownFunc {
+		contexts = append(contexts, ctx)
+		return func() {
+			cancelledBeforeShutdown = append(cancelledBeforeShutdown, ctx.Err() != nil)
+		}
+	})
+
+	done := make(chan struct{})
+	go func() {
+		rununtil.AwaitKillSignalCtx(runner, runner)
+		close(done)
+	}()
+	helperCancelUntilDone(t, done)
+
+	if len(contexts) != 2 {
+		t.Fatalf("expected both runners to have been started, got: %d", len(contexts))
+	}
+	if contexts[0] != contexts[1] {
+		t.Fatal("expected the runners to share the same context")
+	}
+	if !errors.Is(contexts[0].Err(), context.Canceled) {
+		t.Fatalf("expected the context to have been cancelled, got: %v", contexts[0].Err())
+	}
+	for idx, cancelled := range cancelledBeforeShutdown {
+		if !cancelled {
+			t.Fatalf("expected the context to be cancelled before shutdown function %d was called", idx)
+		}
+	}
+}
+
+func TestRununtilAwaitKillSignalCtx_StopsWorker(t *testing.T) {
+	workerStopped := make(chan struct{})
+	runner := rununtil.ContextRunnerFunc(func(ctx context.Context) rununtil.ShutdownFunc {
+		go func() {
+			<-ctx.Done()
+			close(workerStopped)
+		}()
+		return func() {
+			<-workerStopped
+		}
+	})
+
+	done := make(chan struct{})
+	go func() {
+		rununtil.AwaitKillSignalCtx(runner)
+		close(done)
+	}()
+	helperCancelUntilDone(t, done)
+}
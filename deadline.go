@@ -0,0 +1,51 @@
+package rununtil
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+)
+
+// ErrShutdownDeadline is returned when the shutdown functions did not all
+// complete before the shutdown deadline.
+var ErrShutdownDeadline = errors.New("shutdown deadline exceeded")
+
+// CtxShutdownFunc is a variant of ShutdownFunc which is given a context that is
+// done once the shutdown deadline has passed, so that it can be passed straight
+// into, for example, http.Server.Shutdown.
+type CtxShutdownFunc func(ctx context.Context)
+
+// DeadlineRunnerFunc is a variant of RunnerFunc which returns a
+// CtxShutdownFunc, to be run by AwaitKillSignalsDeadline.
+type DeadlineRunnerFunc func() CtxShutdownFunc
+
+// asStarter converts the DeadlineRunnerFunc into a starter whose shutdown never
+// fails.
+func (runner DeadlineRunnerFunc) asStarter() starter {
+	return func(context.Context) (stopFunc, error) {
+		shutdown := runner()
+		if shutdown == nil {
+			return nil, nil
+		}
+		return func(ctx context.Context) error {
+			shutdown(ctx)
+			return nil
+		}, nil
+	}
+}
+
+// AwaitKillSignalsDeadline runs the provided DeadlineRunnerFuncs until the
+// specified signals have been received, at which point it executes the
+// graceful shutdown functions. All of the shutdown functions share a single
+// context which times out after total, e.g. to fit within a Kubernetes pod's
+// terminationGracePeriodSeconds:
+//
+//	err := rununtil.AwaitKillSignalsDeadline(25*time.Second, signals, runner)
+//
+// When the deadline passes AwaitKillSignalsDeadline returns an error wrapping
+// ErrShutdownDeadline, even if some of the shutdown functions are still
+// running. A total of zero means wait forever.
+func AwaitKillSignalsDeadline(total time.Duration, signals []os.Signal, runnerFuncs ...DeadlineRunnerFunc) error {
+	return awaitKillSignals(signals, starters(runnerFuncs), newOptions([]Option{WithShutdownDeadline(total)}))
+}
@@ -0,0 +1,99 @@
+package rununtil_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func helperMakeDeadlineRunner(deadlines chan<- time.Time) rununtil.DeadlineRunnerFunc {
+	return rununtil.DeadlineRunnerFunc(func() rununtil.CtxShutdownFunc {
+		return rununtil.CtxShutdownFunc(func(ctx context.Context) {
+			deadline, _ := ctx.Deadline()
+			deadlines <- deadline
+		})
+	})
+}
+
+func TestRununtilAwaitKillSignalsDeadline_SharedContext(t *testing.T) {
+	deadlines := make(chan time.Time, 2)
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- rununtil.AwaitKillSignalsDeadline(
+			time.Minute,
+			[]os.Signal{syscall.SIGINT},
+			helperMakeDeadlineRunner(deadlines),
+			helperMakeDeadlineRunner(deadlines),
+		)
+	}()
+	if err := helperCancelUntilDone(t, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	first, second := <-deadlines, <-deadlines
+	if first.IsZero() {
+		t.Fatal("expected the shutdown context to have a deadline")
+	}
+	if !first.Equal(second) {
+		t.Fatalf("expected the shutdown functions to share a deadline, got %v and %v", first, second)
+	}
+}
+
+func TestRununtilAwaitKillSignalsDeadline_Exceeded(t *testing.T) {
+	hang := make(chan struct{})
+	defer close(hang)
+	ctxErrs := make(chan error, 1)
+	hangingRunner := rununtil.DeadlineRunnerFunc(func() rununtil.CtxShutdownFunc {
+		return func(ctx context.Context) {
+			<-ctx.Done()
+			ctxErrs <- ctx.Err()
+			<-hang
+		}
+	})
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- rununtil.AwaitKillSignalsDeadline(
+			10*time.Millisecond,
+			[]os.Signal{syscall.SIGINT},
+			hangingRunner,
+		)
+	}()
+	err := helperCancelUntilDone(t, errChan)
+	if !errors.Is(err, rununtil.ErrShutdownDeadline) {
+		t.Fatalf("expected a shutdown deadline error, got: %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the error to wrap context.DeadlineExceeded, got: %v", err)
+	}
+	if ctxErr := <-ctxErrs; ctxErr != context.DeadlineExceeded {
+		t.Fatalf("expected the shutdown context to have timed out, got: %v", ctxErr)
+	}
+}
+
+func TestRununtilAwaitKillSignalsDeadline_Zero(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	slowRunner := rununtil.DeadlineRunnerFunc(func() rununtil.CtxShutdownFunc {
+		return func(ctx context.Context) {
+			time.Sleep(20 * time.Millisecond)
+			hasBeenShutdown.Store(ctx.Err() == nil)
+		}
+	})
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- rununtil.AwaitKillSignalsDeadline(0, []os.Signal{syscall.SIGINT}, slowRunner)
+	}()
+	if err := helperCancelUntilDone(t, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the shutdown function to have been waited for")
+	}
+}
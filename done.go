@@ -0,0 +1,22 @@
+package rununtil
+
+import "os"
+
+// AwaitKillSignalsWithDone runs the provided RunnerFuncs until either the
+// specified signals have been received or the done channel has been closed, at
+// which point it executes the graceful shutdown functions. This plugs in an
+// app's own quit channel, e.g. from a watchdog or a config watcher, without
+// having to call CancelAll:
+//
+//	quit := make(chan struct{})
+//	go watchConfig(quit)
+//	rununtil.AwaitKillSignalsWithDone(quit, signals, NewRunner(logger))
+//
+// The cause of the context given to the runners is ErrCancelled once done has
+// been closed. If a RunnerFunc panics it re-panics with a PanicError, in the
+// same way as AwaitKillSignals.
+func AwaitKillSignalsWithDone(done <-chan struct{}, signals []os.Signal, runnerFuncs ...RunnerFunc) {
+	opts := newOptions(nil)
+	opts.done = done
+	repanic(awaitKillSignals(signals, starters(runnerFuncs), opts))
+}
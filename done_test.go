@@ -0,0 +1,43 @@
+package rununtil_test
+
+import (
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRununtilAwaitKillSignalsWithDone(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	quit := make(chan struct{})
+
+	done := make(chan struct{})
+	go func() {
+		rununtil.AwaitKillSignalsWithDone(quit, []os.Signal{syscall.SIGINT}, helperMakeFakeRunner(&hasBeenShutdown))
+		close(done)
+	}()
+	if !helperWaitFor(func() bool { return rununtil.NumAwaiting() > 0 }) {
+		t.Fatal("expected the await to have started")
+	}
+	close(quit)
+	<-done
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the shutdown function to have been called")
+	}
+}
+
+func TestRununtilAwaitKillSignalsWithDone_CancelAll(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+
+	done := make(chan struct{})
+	go func() {
+		rununtil.AwaitKillSignalsWithDone(make(chan struct{}), []os.Signal{syscall.SIGINT}, helperMakeFakeRunner(&hasBeenShutdown))
+		close(done)
+	}()
+	helperCancelUntilDone(t, done)
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the shutdown function to have been called")
+	}
+}
@@ -0,0 +1,53 @@
+package rununtil
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// Drain is the first phase of shutting down a DrainableRunnerFunc. It should
+// stop accepting new work and then return once the work in flight has finished,
+// or once the context is done because the shutdown deadline has passed.
+type Drain func(ctx context.Context)
+
+// DrainableRunnerFunc is a variant of RunnerFunc which shuts down in two
+// phases, to be run by AwaitKillSignalsDrain. The Drain is executed first,
+// e.g. to stop pulling new jobs and finish the current ones, and then the
+// ShutdownFunc, e.g. to close the connection the jobs were pulled from. A nil
+// Drain means there is nothing to drain, and a nil ShutdownFunc means there is
+// nothing to shut down once it has drained.
+type DrainableRunnerFunc func() (Drain, ShutdownFunc)
+
+// asStarter converts the DrainableRunnerFunc into a starter whose shutdown
+// drains and then shuts down, and never fails.
+func (runner DrainableRunnerFunc) asStarter() starter {
+	return func(context.Context) (stopFunc, error) {
+		drain, shutdown := runner()
+		if drain == nil && shutdown == nil {
+			return nil, nil
+		}
+		return func(ctx context.Context) error {
+			if drain != nil {
+				drain(ctx)
+			}
+			if shutdown != nil {
+				shutdown()
+			}
+			return nil
+		}, nil
+	}
+}
+
+// AwaitKillSignalsDrain runs the provided DrainableRunnerFuncs until the
+// specified signals have been received, at which point it drains and then shuts
+// each of them down in turn. All of the Drains share a single context which
+// times out after total, in the same way as AwaitKillSignalsDeadline:
+//
+//	err := rununtil.AwaitKillSignalsDrain(25*time.Second, signals, worker)
+//
+// When the deadline passes AwaitKillSignalsDrain returns an error wrapping
+// ErrShutdownDeadline. A total of zero means wait forever.
+func AwaitKillSignalsDrain(total time.Duration, signals []os.Signal, runnerFuncs ...DrainableRunnerFunc) error {
+	return awaitKillSignals(signals, starters(runnerFuncs), newOptions([]Option{WithShutdownDeadline(total)}))
+}
@@ -0,0 +1,85 @@
+package rununtil_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRununtilAwaitKillSignalsDrain(t *testing.T) {
+	events := make(chan string, 4)
+	worker := func(name string) rununtil.DrainableRunnerFunc {
+		return func() (rununtil.Drain, rununtil.ShutdownFunc) {
+			drain := func(ctx context.Context) {
+				events <- "drain " + name
+			}
+			shutdown := func() {
+				events <- "shutdown " + name
+			}
+			return drain, shutdown
+		}
+	}
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- rununtil.AwaitKillSignalsDrain(time.Minute, []os.Signal{syscall.SIGINT}, worker("first"), worker("second"))
+	}()
+	if err := helperCancelUntilDone(t, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{"drain second", "shutdown second", "drain first", "shutdown first"} {
+		if event := <-events; event != expected {
+			t.Fatalf("expected %q, got: %q", expected, event)
+		}
+	}
+}
+
+func TestRununtilAwaitKillSignalsDrain_DrainOnly(t *testing.T) {
+	drained := make(chan struct{})
+	worker := rununtil.DrainableRunnerFunc(func() (rununtil.Drain, rununtil.ShutdownFunc) {
+		return func(ctx context.Context) { close(drained) }, nil
+	})
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- rununtil.AwaitKillSignalsDrain(time.Minute, []os.Signal{syscall.SIGINT}, worker)
+	}()
+	if err := helperCancelUntilDone(t, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	select {
+	case <-drained:
+	default:
+		t.Fatal("expected the runner to have been drained without a shutdown function")
+	}
+}
+
+func TestRununtilAwaitKillSignalsDrain_Deadline(t *testing.T) {
+	shutdown := make(chan struct{})
+	worker := rununtil.DrainableRunnerFunc(func() (rununtil.Drain, rununtil.ShutdownFunc) {
+		drain := func(ctx context.Context) {
+			// the work in flight never finishes
+			<-ctx.Done()
+		}
+		return drain, func() { close(shutdown) }
+	})
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- rununtil.AwaitKillSignalsDrain(10*time.Millisecond, []os.Signal{syscall.SIGINT}, worker)
+	}()
+	if err := helperCancelUntilDone(t, errChan); !errors.Is(err, rununtil.ErrShutdownDeadline) {
+		t.Fatalf("expected a shutdown deadline error, got: %v", err)
+	}
+	select {
+	case <-shutdown:
+	case <-time.After(time.Second):
+		t.Fatal("expected the shutdown function to be executed once the drain had given up")
+	}
+}
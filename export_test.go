@@ -0,0 +1,88 @@
+package rununtil
+
+import (
+	"context"
+	"os"
+	"os/signal"
+)
+
+// NumAwaiting returns the number of awaits that CancelAll would currently
+// cancel. It lets the tests wait for the awaits to actually start rather than
+// sleeping for an arbitrary amount of time.
+func NumAwaiting() int {
+	globalCanceller.mux.Lock()
+	defer globalCanceller.mux.Unlock()
+	return len(globalCanceller.signals)
+}
+
+// NumAwaiting returns the number of awaits that Cancel would currently cancel.
+func (r *Runner) NumAwaiting() int {
+	r.canceller.mux.Lock()
+	defer r.canceller.mux.Unlock()
+	return len(r.canceller.signals)
+}
+
+// SetExit replaces the function which Main exits with, returning a function
+// which restores it.
+func SetExit(fn func(code int)) (restore func()) {
+	exit = fn
+	return func() {
+		exit = os.Exit
+	}
+}
+
+// SetSignalNotify replaces the function which starts listening for signals,
+// returning a function which restores it.
+func SetSignalNotify(fn func(c chan<- os.Signal, sig ...os.Signal)) (restore func()) {
+	signalNotify = fn
+	return func() {
+		signalNotify = signal.Notify
+	}
+}
+
+// SetSignalStop replaces the function which stops listening for signals,
+// returning a function which restores it.
+func SetSignalStop(fn func(c chan<- os.Signal)) (restore func()) {
+	signalStop = fn
+	return func() {
+		signalStop = signal.Stop
+	}
+}
+
+// AwaitReason runs the ReasonRunnerFuncs with the options, and the done
+// channel if it isn't nil, until one of the signals is received, returning why
+// the await stopped. It lets the tests check the reasons which only an Option
+// or AwaitKillSignalsWithDone can cause.
+func AwaitReason(done <-chan struct{}, signals []os.Signal, opts []Option, runnerFuncs ...ReasonRunnerFunc) TerminationReason {
+	o := newOptions(opts)
+	o.done = done
+	return awaitUntil(context.Background(), signals, starters(runnerFuncs), o)
+}
+
+// ShutdownAgain executes the shutdown functions of the Runner's most recent
+// await again, as a second way of triggering shutdown would, returning their
+// errors.
+func (r *Runner) ShutdownAgain() error {
+	r.mux.Lock()
+	s := r.current
+	r.mux.Unlock()
+	return shutdownAll(context.Background(), s.stop(), s.opts)
+}
+
+// ResetOnSignal forgets the actions which have been registered with OnSignal,
+// so that they don't run during the other tests.
+func ResetOnSignal() {
+	defaultRunner.mux.Lock()
+	defer defaultRunner.mux.Unlock()
+	defaultRunner.actions = nil
+}
+
+// SetExec replaces the function which re-executes the binary, returning a
+// function which restores it.
+func SetExec(fn func(argv0 string, argv []string, envv []string) error) (restore func()) {
+	original := execProcess
+	execProcess = fn
+	return func() {
+		execProcess = original
+	}
+}
@@ -0,0 +1,64 @@
+package rununtil
+
+import (
+	"sync"
+	"time"
+)
+
+// WithFallback returns a ShutdownFunc which executes graceful and, if it hasn't
+// completed within the timeout, executes fallback as well, e.g. to force close
+// a resource whose graceful close has hung. This is the same escalation as a
+// gRPC server's GracefulStop to Stop:
+//
+//	return rununtil.WithFallback(srv.GracefulStop, 10*time.Second, srv.Stop)
+//
+// The returned ShutdownFunc blocks until graceful has completed or, once the
+// fallback has been executed, until either of them has completed. graceful and
+// fallback are each executed at most once, however many times the returned
+// ShutdownFunc is called. A timeout of zero means wait for graceful to
+// complete, and never execute the fallback. If either of them panics, the
+// returned ShutdownFunc re-panics with a PanicError.
+func WithFallback(graceful ShutdownFunc, timeout time.Duration, fallback ShutdownFunc) ShutdownFunc {
+	var once sync.Once
+	return ShutdownFunc(func() {
+		var panicErr *PanicError
+		once.Do(func() {
+			panicErr = runWithFallback(graceful, timeout, fallback)
+		})
+		if panicErr != nil {
+			panic(panicErr)
+		}
+	})
+}
+
+// runWithFallback executes graceful, executing fallback too if graceful hasn't
+// completed within the timeout, and returns a PanicError if the one which
+// completed panicked.
+func runWithFallback(graceful ShutdownFunc, timeout time.Duration, fallback ShutdownFunc) *PanicError {
+	if timeout <= 0 {
+		return callSafely(graceful)
+	}
+
+	gracefulDone := make(chan *PanicError, 1)
+	go func() {
+		gracefulDone <- callSafely(graceful)
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case panicErr := <-gracefulDone:
+		return panicErr
+	case <-timer.C:
+	}
+
+	fallbackDone := make(chan *PanicError, 1)
+	go func() {
+		fallbackDone <- callSafely(fallback)
+	}()
+	select {
+	case panicErr := <-gracefulDone:
+		return panicErr
+	case panicErr := <-fallbackDone:
+		return panicErr
+	}
+}
@@ -0,0 +1,96 @@
+package rununtil_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestWithFallback(t *testing.T) {
+	var gracefulCalls, fallbackCalls atomic.Int32
+	graceful := func() { gracefulCalls.Add(1) }
+	fallback := func() { fallbackCalls.Add(1) }
+
+	shutdown := rununtil.WithFallback(graceful, time.Second, fallback)
+	shutdown()
+	shutdown()
+
+	if n := gracefulCalls.Load(); n != 1 {
+		t.Fatalf("expected graceful to have been executed once, got %d calls", n)
+	}
+	if n := fallbackCalls.Load(); n != 0 {
+		t.Fatalf("expected the fallback not to have been executed, got %d calls", n)
+	}
+}
+
+func TestWithFallback_Hangs(t *testing.T) {
+	var gracefulCalls, fallbackCalls atomic.Int32
+	hang := make(chan struct{})
+	defer close(hang)
+	graceful := func() {
+		gracefulCalls.Add(1)
+		<-hang
+	}
+	fallback := func() { fallbackCalls.Add(1) }
+
+	shutdown := rununtil.WithFallback(graceful, 20*time.Millisecond, fallback)
+	finished := make(chan struct{})
+	go func() {
+		shutdown()
+		shutdown()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("expected the shutdown to have returned once the fallback had completed")
+	}
+
+	if n := gracefulCalls.Load(); n != 1 {
+		t.Fatalf("expected graceful to have been executed once, got %d calls", n)
+	}
+	if n := fallbackCalls.Load(); n != 1 {
+		t.Fatalf("expected the fallback to have been executed once, got %d calls", n)
+	}
+}
+
+func TestWithFallback_UnblocksGraceful(t *testing.T) {
+	stopped := make(chan struct{})
+	hang := make(chan struct{})
+	defer close(hang)
+	// like GracefulStop and Stop, the fallback makes graceful return but
+	// itself carries on for a while
+	graceful := func() { <-stopped }
+	fallback := func() {
+		close(stopped)
+		<-hang
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		rununtil.WithFallback(graceful, 20*time.Millisecond, fallback)()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("expected the shutdown to have returned once graceful had completed")
+	}
+}
+
+func TestWithFallback_Panics(t *testing.T) {
+	defer func() {
+		var panicErr *rununtil.PanicError
+		if recovered, ok := recover().(error); !ok || !errors.As(recovered, &panicErr) {
+			t.Fatalf("expected a panic error, got: %v", recovered)
+		}
+		if panicErr.Value != "boom" {
+			t.Fatalf("expected the panic value to be boom, got: %v", panicErr.Value)
+		}
+	}()
+
+	rununtil.WithFallback(func() { panic("boom") }, time.Second, func() {})()
+}
@@ -0,0 +1,32 @@
+package rununtil
+
+// WithFinalFlush calls flush as the very last step of the await, once the
+// shutdown has completed and been logged and reported, so that a buffering
+// logger, such as zap or zerolog, doesn't lose the lines logged during
+// shutdown when main returns straight after the await:
+//
+//	rununtil.New(rununtil.WithLogger(logger), rununtil.WithFinalFlush(func() {
+//		_ = zapLogger.Sync()
+//	}))
+//
+// The flushes are called in the order they were added, and one which panics
+// is recovered from, so that it can't get in the way of the process exiting.
+func WithFinalFlush(flush func()) Option {
+	return func(o *options) {
+		o.finalFlush = append(o.finalFlush, flush)
+	}
+}
+
+// flush calls the WithFinalFlush functions, unless they have already been
+// called, e.g. before a re-exec which then failed.
+func (s *session) flush() {
+	if s.flushed {
+		return
+	}
+	s.flushed = true
+	for _, flush := range s.opts.finalFlush {
+		if panicErr := callSafely(flush); panicErr != nil {
+			s.opts.logger.Errorf("final flush panicked: %v", panicErr.Value)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package rununtil_test
+
+import (
+	"testing"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRununtilWithFinalFlush(t *testing.T) {
+	logger := &fakeLogger{}
+	var flushedAfterShutdown bool
+	r := rununtil.New(rununtil.WithLogger(logger), rununtil.WithFinalFlush(func() {
+		flushedAfterShutdown = logger.contains("shutdown complete")
+	}))
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(func() rununtil.ShutdownFunc { return func() {} })
+	}()
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !flushedAfterShutdown {
+		t.Fatal("expected the flush to have been called once the shutdown had been logged")
+	}
+}
+
+func TestRununtilWithFinalFlush_Panics(t *testing.T) {
+	logger := &fakeLogger{}
+	var flushed bool
+	r := rununtil.New(
+		rununtil.WithLogger(logger),
+		rununtil.WithFinalFlush(func() { panic("sync /dev/stderr: invalid argument") }),
+		rununtil.WithFinalFlush(func() { flushed = true }),
+	)
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await()
+	}()
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !flushed {
+		t.Fatal("expected the flush after the panicking one to have been called")
+	}
+	if !logger.contains("final flush panicked: sync /dev/stderr: invalid argument") {
+		t.Fatalf("expected the panic to have been logged, got: %v", logger.lines)
+	}
+}
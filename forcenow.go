@@ -0,0 +1,33 @@
+package rununtil
+
+// ForceNow hurries the shutdown of the package level functions' awaits, see
+// Runner.ForceNow.
+func ForceNow() {
+	defaultRunner.ForceNow()
+}
+
+// ForceNow hurries the shutdown of all of the Runner's awaits, e.g. when an
+// operator decides that they can't wait for it in an emergency. The rest of
+// the WithLameDuckDelay delay, and any remaining WithShutdownStagger
+// intervals, are skipped so that the remaining shutdown functions are executed
+// straight away. Receiving another kill signal during the lame duck delay
+// does the same, whereas one received once the shutdown functions are being
+// executed is only logged, unless WithForceQuitOnSecondSignal has been given
+// in which case the process exits with 130. An await that hasn't begun
+// shutting down yet is hurried once it does.
+func (r *Runner) ForceNow() {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	for s := range r.sessions {
+		s.forceNow()
+	}
+}
+
+// forceNow hurries the session's shutdown, skipping any of its remaining
+// delays.
+func (s *session) forceNow() {
+	s.hurryOnce.Do(func() {
+		s.opts.logger.Infof("hurrying shutdown")
+		close(s.hurry)
+	})
+}
@@ -0,0 +1,102 @@
+package rununtil_test
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRunner_ForceNow_LameDuckDelay(t *testing.T) {
+	clock := newFakeClock()
+	r := rununtil.New(rununtil.WithClock(clock), rununtil.WithLameDuckDelay(time.Hour))
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await()
+	}()
+	helperKeepCancelling(t, r.Cancel, clock.waiting)
+
+	r.ForceNow()
+	select {
+	case err := <-errChan:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected ForceNow to have skipped the lame duck delay")
+	}
+}
+
+func TestRunner_ForceNow_SecondSignal(t *testing.T) {
+	clock := newFakeClock()
+	signals := make(chan os.Signal)
+	r := rununtil.New(rununtil.WithClock(clock), rununtil.WithLameDuckDelay(time.Hour), rununtil.WithSignalSource(signals))
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await()
+	}()
+	signals <- syscall.SIGTERM
+	<-clock.waiting
+
+	signals <- syscall.SIGTERM
+	select {
+	case err := <-errChan:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the second signal to have skipped the lame duck delay")
+	}
+}
+
+func TestRunner_ForceNow_Stagger(t *testing.T) {
+	clock := newFakeClock()
+	shutdowns := make(chan int, 3)
+	r := rununtil.New(rununtil.WithClock(clock), rununtil.WithShutdownStagger(time.Hour))
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(helperMakeStaggeredRunners(shutdowns, 3)...)
+	}()
+	helperKeepCancelling(t, r.Cancel, clock.waiting)
+
+	r.ForceNow()
+	select {
+	case err := <-errChan:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected ForceNow to have skipped the rest of the stagger")
+	}
+	if len(shutdowns) != 3 {
+		t.Fatalf("expected all of the runners to have been shut down, got %d", len(shutdowns))
+	}
+}
+
+func TestRununtilForceNow(t *testing.T) {
+	clock := newFakeClock()
+	errChan := make(chan error)
+	go func() {
+		_, err := rununtil.AwaitKillSignalsFull([]rununtil.Option{
+			rununtil.WithClock(clock),
+			rununtil.WithLameDuckDelay(time.Hour),
+		})
+		errChan <- err
+	}()
+	helperKeepCancelling(t, rununtil.CancelAll, clock.waiting)
+
+	rununtil.ForceNow()
+	select {
+	case err := <-errChan:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected ForceNow to have skipped the lame duck delay")
+	}
+}
@@ -0,0 +1,51 @@
+package rununtil
+
+// forceQuitExitCode is what the process exits with when it is forced to quit,
+// the conventional exit code for a process killed by SIGINT.
+const forceQuitExitCode = 130
+
+// WithForceQuitOnSecondSignal keeps listening for the kill signals once
+// graceful shutdown has begun, and exits the process with 130 straight away if
+// another one is received, so that pressing Ctrl+C a second time force quits.
+// WithSignalCoalescing stops a quick repeat of the first signal from counting.
+func WithForceQuitOnSecondSignal() Option {
+	return func(o *options) {
+		o.forceQuit = true
+	}
+}
+
+// listenDuringShutdown reads the signals in a go routine until the returned
+// function is called. With WithForceQuitOnSecondSignal a kill signal exits the
+// process, and otherwise the signals are ignored, other than being logged.
+func (s *session) listenDuringShutdown() (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		signals := s.signals
+		for {
+			select {
+			case sig, ok := <-signals:
+				if !ok {
+					signals = nil
+					continue
+				}
+				if !s.isKillSignal(sig) || s.coalesced(sig) {
+					continue
+				}
+				if !s.opts.forceQuit {
+					s.opts.logger.Infof("received signal %v during shutdown, which is already running", sig)
+					continue
+				}
+				s.opts.logger.Errorf("received signal %v during shutdown, exiting immediately", sig)
+				s.opts.exitProcess(forceQuitExitCode)
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
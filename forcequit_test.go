@@ -0,0 +1,58 @@
+package rununtil_test
+
+import (
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+// helperForceQuit sends a kill signal to start shutdown, and then another one
+// while the runner is being shut down.
+func helperForceQuit(t *testing.T, opts ...rununtil.Option) {
+	t.Helper()
+	signals := make(chan os.Signal)
+	shuttingDown := make(chan struct{})
+	hang := make(chan struct{})
+	opts = append(opts, rununtil.WithSignalSource(signals), rununtil.WithForceQuitOnSecondSignal())
+	r := rununtil.New(opts...)
+	hangingRunner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		return func() {
+			close(shuttingDown)
+			<-hang
+		}
+	})
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(hangingRunner)
+	}()
+	signals <- syscall.SIGINT
+	<-shuttingDown
+	signals <- syscall.SIGINT
+	t.Cleanup(func() {
+		close(hang)
+		if err := <-errChan; err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestRununtilWithForceQuitOnSecondSignal(t *testing.T) {
+	codes := helperCaptureExit(t)
+	helperForceQuit(t)
+	if code := <-codes; code != 130 {
+		t.Fatalf("expected to exit with 130, got: %d", code)
+	}
+}
+
+func TestRununtilWithExitFunc(t *testing.T) {
+	codes := make(chan int, 1)
+	helperForceQuit(t, rununtil.WithExitFunc(func(code int) {
+		codes <- code
+	}))
+	if code := <-codes; code != 130 {
+		t.Fatalf("expected the exit func to be called with 130, got: %d", code)
+	}
+}
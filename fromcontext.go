@@ -0,0 +1,42 @@
+package rununtil
+
+import (
+	"context"
+	"os"
+)
+
+// FromContext runs the provided RunnerFuncs until the context is done, or
+// CancelAll has been called, at which point it executes the graceful shutdown
+// functions. It doesn't listen for any signals itself, so that the runners can
+// be plugged into a context which is already cancelled by the standard
+// library's signal handling:
+//
+//	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+//	defer stop()
+//	rununtil.FromContext(ctx, NewRunner(logger))
+//
+// If a RunnerFunc panics it re-panics with a PanicError, in the same way
+// as AwaitKillSignals.
+func FromContext(ctx context.Context, runnerFuncs ...RunnerFunc) {
+	opts := newOptions(nil)
+	opts.ctx = ctx
+	// a signal source which never delivers a signal stops the await from
+	// listening for them
+	opts.signalSource = make(chan os.Signal)
+	repanic(defaultRunner.newSession(nil, opts).run(starters(runnerFuncs)))
+}
+
+// AwaitKillSignalsWithParent runs the provided RunnerFuncs until it receives
+// one of the specified signals, or the parent context is done, at which point
+// it executes the graceful shutdown functions. It is the inbound counterpart
+// to NewLifecycle's context, for a service which is nested in a larger one
+// with its own root context:
+//
+//	rununtil.AwaitKillSignalsWithParent(ctx, []os.Signal{syscall.SIGTERM}, NewRunner(logger))
+//
+// It is the same as AwaitUntil, for when it doesn't matter which of them it
+// was. If a RunnerFunc panics it re-panics with a PanicError, in the same way
+// as AwaitKillSignals.
+func AwaitKillSignalsWithParent(parent context.Context, signals []os.Signal, runnerFuncs ...RunnerFunc) {
+	AwaitUntil(parent, signals, runnerFuncs...)
+}
@@ -0,0 +1,88 @@
+package rununtil_test
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRununtilFromContext(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		rununtil.FromContext(ctx, helperMakeFakeRunner(&hasBeenShutdown))
+		close(done)
+	}()
+	if !helperWaitFor(func() bool { return rununtil.NumAwaiting() > 0 }) {
+		t.Fatal("expected the await to have started")
+	}
+	if hasBeenShutdown.Load() {
+		t.Fatal("expected the runner to still be running")
+	}
+	cancel()
+	<-done
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the shutdown function to have been called")
+	}
+}
+
+func TestRununtilFromContext_CancelAll(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+
+	done := make(chan struct{})
+	go func() {
+		rununtil.FromContext(context.Background(), helperMakeFakeRunner(&hasBeenShutdown))
+		close(done)
+	}()
+	helperCancelUntilDone(t, done)
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the shutdown function to have been called")
+	}
+}
+
+func TestRununtilAwaitKillSignalsWithParent(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	parent, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		rununtil.AwaitKillSignalsWithParent(parent, []os.Signal{syscall.SIGINT}, helperMakeFakeRunner(&hasBeenShutdown))
+		close(done)
+	}()
+	if !helperWaitFor(func() bool { return rununtil.NumAwaiting() > 0 }) {
+		t.Fatal("expected the await to have started")
+	}
+	if hasBeenShutdown.Load() {
+		t.Fatal("expected the runner to still be running")
+	}
+	cancel()
+	<-done
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the shutdown function to have been called")
+	}
+}
+
+func TestRununtilAwaitKillSignalsWithParent_Signal(t *testing.T) {
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("unexpected error occurred: %v", err)
+	}
+	var sentSignal, hasBeenShutdown atomic.Bool
+
+	go helperSendSignal(t, p, &sentSignal, syscall.SIGINT, time.Millisecond)
+	rununtil.AwaitKillSignalsWithParent(context.Background(), []os.Signal{syscall.SIGINT}, helperMakeFakeRunner(&hasBeenShutdown))
+
+	if !sentSignal.Load() {
+		t.Fatal("expected the await to have waited for the signal")
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the shutdown function to have been called")
+	}
+}
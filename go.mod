@@ -1,8 +1,5 @@
 module github.com/kaluza-tech/rununtil
 
-go 1.12
+go 1.20
 
-require (
-	github.com/google/uuid v1.1.1
-	github.com/pkg/errors v0.8.1
-)
+require golang.org/x/sync v0.10.0
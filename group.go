@@ -0,0 +1,52 @@
+package rununtil
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// AwaitKillSignalsGroup runs each of the runners in its own go routine, as part
+// of an errgroup.Group, until the specified signals have been received. It is
+// for services where one critical go routine failing should bring the whole
+// process down: as soon as any runner returns an error the group's context is
+// cancelled, so the other runners can see ctx.Done() and return too, and
+// AwaitKillSignalsGroup returns that first error. A kill signal, or CancelAll,
+// also cancels the group's context and AwaitKillSignalsGroup then waits for
+// all of the runners to return. Runners which return ctx.Err() once they have
+// been told to stop are not treated as having failed.
+func AwaitKillSignalsGroup(signals []os.Signal, runners ...func(ctx context.Context) error) error {
+	s := defaultRunner.newSession(signals, newOptions(nil))
+
+	var groupErr error
+	start := ContextRunnerFunc(func(ctx context.Context) ShutdownFunc {
+		g, groupCtx := errgroup.WithContext(ctx)
+		for _, runner := range runners {
+			runner := runner
+			g.Go(func() error {
+				return runner(groupCtx)
+			})
+		}
+
+		finished := make(chan struct{})
+		go func() {
+			groupErr = g.Wait()
+			close(finished)
+			// there is nothing left to run, either because a runner
+			// failed or because they have all returned
+			defaultRunner.CancelKey(s.key)
+		}()
+
+		return func() {
+			<-finished
+		}
+	})
+
+	err := s.run([]component{{start: start.asStarter()}})
+	if errors.Is(groupErr, context.Canceled) {
+		groupErr = nil
+	}
+	return errors.Join(groupErr, err)
+}
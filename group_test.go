@@ -0,0 +1,60 @@
+package rununtil_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRununtilAwaitKillSignalsGroup_RunnerFails(t *testing.T) {
+	failure := errors.New("lost connection")
+	otherStopped := make(chan struct{})
+
+	err := rununtil.AwaitKillSignalsGroup(
+		[]os.Signal{syscall.SIGINT},
+		func(ctx context.Context) error {
+			<-ctx.Done()
+			close(otherStopped)
+			return ctx.Err()
+		},
+		func(ctx context.Context) error {
+			return failure
+		},
+	)
+	if !errors.Is(err, failure) {
+		t.Fatalf("expected the runner's error to be returned, got: %v", err)
+	}
+	select {
+	case <-otherStopped:
+	default:
+		t.Fatal("expected the other runner to have stopped")
+	}
+}
+
+func TestRununtilAwaitKillSignalsGroup_Cancelled(t *testing.T) {
+	stopped := make(chan struct{})
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- rununtil.AwaitKillSignalsGroup(
+			[]os.Signal{syscall.SIGINT},
+			func(ctx context.Context) error {
+				<-ctx.Done()
+				close(stopped)
+				return ctx.Err()
+			},
+		)
+	}()
+	if err := helperCancelUntilDone(t, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	select {
+	case <-stopped:
+	default:
+		t.Fatal("expected the runner to have stopped")
+	}
+}
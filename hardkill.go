@@ -0,0 +1,45 @@
+package rununtil
+
+import "time"
+
+// hardKillExitCode is what the process exits with when WithHardKillAfter
+// gives up on the shutdown.
+const hardKillExitCode = 1
+
+// WithHardKillAfter is the last resort for a shutdown which is deadlocked, e.g.
+// by a shutdown function which never returns and has no shutdown timeout. If
+// the await is still shutting down d after the shutdown began, including any
+// lame duck delay, it logs an error and exits the process with 1, through the
+// WithExitFunc if there is one, so that the process exits on its own terms
+// rather than being sent SIGKILL at the end of Kubernetes'
+// terminationGracePeriodSeconds. The watchdog is stopped before the await
+// returns. A duration of zero, the default, never exits.
+func WithHardKillAfter(d time.Duration) Option {
+	return func(o *options) {
+		o.hardKillAfter = d
+	}
+}
+
+// startWatchdog exits the process if the session hasn't finished shutting
+// down within the WithHardKillAfter duration, until stopWatchdog is called.
+func (s *session) startWatchdog() {
+	if s.opts.hardKillAfter <= 0 {
+		return
+	}
+	expired := s.opts.clock.After(s.opts.hardKillAfter)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-expired:
+			s.opts.logger.Errorf("shutdown still hasn't completed after %v, exiting immediately", s.opts.hardKillAfter)
+			s.opts.exitProcess(hardKillExitCode)
+		case <-done:
+		}
+	}()
+	s.stopWatchdog = func() {
+		close(done)
+		<-stopped
+	}
+}
@@ -0,0 +1,68 @@
+package rununtil_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRununtilWithHardKillAfter(t *testing.T) {
+	clock := newFakeClock()
+	logger := &fakeLogger{}
+	hang := make(chan struct{})
+	exited := make(chan int, 1)
+	r := rununtil.New(
+		rununtil.WithClock(clock),
+		rununtil.WithLogger(logger),
+		rununtil.WithHardKillAfter(time.Minute),
+		rununtil.WithExitFunc(func(code int) { exited <- code }),
+	)
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(func() rununtil.ShutdownFunc {
+			return func() { <-hang }
+		})
+	}()
+	helperKeepCancelling(t, r.Cancel, clock.waiting)
+
+	clock.advance(time.Minute)
+	select {
+	case code := <-exited:
+		if code != 1 {
+			t.Fatalf("expected to exit with 1, got: %d", code)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the deadlocked shutdown to have exited the process")
+	}
+	if !logger.contains("shutdown still hasn't completed after 1m0s, exiting immediately") {
+		t.Fatalf("expected the exit to have been logged, got: %v", logger.lines)
+	}
+	close(hang)
+	if err := <-errChan; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRununtilWithHardKillAfter_Completed(t *testing.T) {
+	clock := newFakeClock()
+	r := rununtil.New(
+		rununtil.WithClock(clock),
+		rununtil.WithHardKillAfter(time.Minute),
+		rununtil.WithExitFunc(func(code int) {
+			t.Errorf("expected not to exit, got: %d", code)
+		}),
+	)
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(func() rununtil.ShutdownFunc { return func() {} })
+	}()
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	clock.advance(time.Minute)
+	// give the watchdog the chance to wrongly exit
+	time.Sleep(10 * time.Millisecond)
+}
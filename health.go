@@ -0,0 +1,75 @@
+package rununtil
+
+import (
+	"context"
+	"errors"
+)
+
+// HealthCheck reports whether a runner is healthy, returning an error if it is
+// not.
+type HealthCheck func(ctx context.Context) error
+
+// HealthRunnerFunc is a variant of RunnerFunc which also returns a HealthCheck,
+// to be run by a Runner with HealthRunner so that the Runner can report the
+// health of everything it is running.
+type HealthRunnerFunc func() (ShutdownFunc, HealthCheck)
+
+// HealthRunner converts the HealthRunnerFunc into a RunnerFunc whose
+// HealthCheck is registered with the Runner while it is running, from when it
+// starts until its ShutdownFunc has been executed:
+//
+//	go r.Await(r.HealthRunner(db), r.HealthRunner(queue))
+//	http.HandleFunc("/healthz", func(w http.ResponseWriter, req *http.Request) {
+//		if err := r.Healthy(req.Context()); err != nil {
+//			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+//		}
+//	})
+func (r *Runner) HealthRunner(runner HealthRunnerFunc) RunnerFunc {
+	return func() ShutdownFunc {
+		shutdown, check := runner()
+		if check == nil {
+			return shutdown
+		}
+		registered := &check
+		r.mux.Lock()
+		r.checks = append(r.checks, registered)
+		r.mux.Unlock()
+		return func() {
+			defer r.forgetCheck(registered)
+			if shutdown != nil {
+				shutdown()
+			}
+		}
+	}
+}
+
+// Healthy calls the HealthChecks of all of the Runner's running
+// HealthRunnerFuncs, one at a time, and returns their combined errors. It
+// returns nil if they are all healthy, or if there are none.
+func (r *Runner) Healthy(ctx context.Context) error {
+	r.mux.Lock()
+	checks := make([]HealthCheck, 0, len(r.checks))
+	for _, check := range r.checks {
+		checks = append(checks, *check)
+	}
+	r.mux.Unlock()
+
+	var errs []error
+	for _, check := range checks {
+		errs = append(errs, check(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// forgetCheck stops calling the HealthCheck once its runner has been shut
+// down.
+func (r *Runner) forgetCheck(registered *HealthCheck) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	for idx, check := range r.checks {
+		if check == registered {
+			r.checks = append(r.checks[:idx], r.checks[idx+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package rununtil_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func helperMakeHealthRunner(err error) rununtil.HealthRunnerFunc {
+	return func() (rununtil.ShutdownFunc, rununtil.HealthCheck) {
+		return func() {}, func(context.Context) error {
+			return err
+		}
+	}
+}
+
+func TestRunner_Healthy(t *testing.T) {
+	unhealthy := errors.New("connection refused")
+	r := rununtil.New()
+
+	if err := r.Healthy(context.Background()); err != nil {
+		t.Fatalf("expected a Runner with no health checks to be healthy, got: %v", err)
+	}
+
+	r.AwaitInBackground(r.HealthRunner(helperMakeHealthRunner(nil)))
+	if err := r.Healthy(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := r.Add(r.HealthRunner(helperMakeHealthRunner(unhealthy))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.Healthy(context.Background()); !errors.Is(err, unhealthy) {
+		t.Fatalf("expected the unhealthy runner's error, got: %v", err)
+	}
+
+	if err := r.Stop(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.Healthy(context.Background()); err != nil {
+		t.Fatalf("expected the health checks to have been removed on shutdown, got: %v", err)
+	}
+}
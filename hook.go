@@ -0,0 +1,34 @@
+package rununtil
+
+import "context"
+
+// runnerKey is the key of the Runner in the contexts that it gives out.
+type runnerKey struct{}
+
+// RunnerFromContext returns the Runner whose await the context belongs to, for
+// the contexts given to ContextRunnerFuncs and returned by NewLifecycle, so
+// that deeply nested code which only has the context can add to it without the
+// Runner being passed down through every layer:
+//
+//	if r, ok := rununtil.RunnerFromContext(ctx); ok {
+//		err := r.AddShutdownHook(cache.Flush)
+//	}
+//
+// The Runner of the package level functions, such as AwaitKillSignalCtx, is
+// the default Runner. It reports false if the context didn't come from an
+// await.
+func RunnerFromContext(ctx context.Context) (*Runner, bool) {
+	r, ok := ctx.Value(runnerKey{}).(*Runner)
+	return r, ok
+}
+
+// AddShutdownHook adds a ShutdownFunc to the Runner's current await, in the
+// same way as Add, so that something which is initialised lazily, such as a
+// cache, is still shut down along with the rest even though it was set up after
+// the await began. Like every runner, the hooks are shut down in the reverse
+// order to which they were added, so a hook added late is executed before the
+// runners which were already running. It returns ErrShuttingDown, and the hook
+// is never executed, if the Runner's shutdown has already begun.
+func (r *Runner) AddShutdownHook(hook ShutdownFunc) error {
+	return r.Add(func() ShutdownFunc { return hook })
+}
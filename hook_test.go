@@ -0,0 +1,84 @@
+package rununtil_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRununtilRunnerFromContext(t *testing.T) {
+	var hookRan atomic.Bool
+	added := make(chan error, 1)
+	runner := rununtil.ContextRunnerFunc(func(ctx context.Context) rununtil.ShutdownFunc {
+		go func() {
+			// nested code which only has the context adds a hook once
+			// the await has begun
+			r, ok := rununtil.RunnerFromContext(ctx)
+			if !ok {
+				added <- errors.New("expected the context to carry the Runner")
+				return
+			}
+			added <- r.AddShutdownHook(func() { hookRan.Store(true) })
+		}()
+		return func() {}
+	})
+
+	done := make(chan struct{})
+	go func() {
+		rununtil.AwaitKillSignalsCtx([]os.Signal{syscall.SIGINT}, runner)
+		close(done)
+	}()
+	if err := <-added; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	helperCancelUntilDone(t, done)
+
+	if !hookRan.Load() {
+		t.Fatal("expected the hook which was added late to have been executed")
+	}
+}
+
+func TestRununtilRunnerFromContext_Lifecycle(t *testing.T) {
+	ctx, await := rununtil.NewLifecycle()
+	r, ok := rununtil.RunnerFromContext(ctx)
+	if !ok {
+		t.Fatal("expected the lifecycle context to carry the Runner")
+	}
+	if rununtil.Awaiter(r) != rununtil.Default() {
+		t.Fatal("expected the lifecycle context to carry the default Runner")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		await()
+		close(done)
+	}()
+	helperCancelUntilDone(t, done)
+}
+
+func TestRununtilRunnerFromContext_NotFromAwait(t *testing.T) {
+	if _, ok := rununtil.RunnerFromContext(context.Background()); ok {
+		t.Fatal("expected a context which didn't come from an await not to carry a Runner")
+	}
+}
+
+func TestRunner_AddShutdownHook_ShuttingDown(t *testing.T) {
+	var hookRan atomic.Bool
+	r := rununtil.New()
+	errChan := helperAwaitStarted(t, r)
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := r.AddShutdownHook(func() { hookRan.Store(true) }); !errors.Is(err, rununtil.ErrShuttingDown) {
+		t.Fatalf("expected a shutting down error, got: %v", err)
+	}
+	if hookRan.Load() {
+		t.Fatal("expected the hook not to have been executed")
+	}
+}
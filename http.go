@@ -0,0 +1,116 @@
+package rununtil
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// HTTPServerOption configures the RunnerFuncs created by HTTPServerRunner and
+// HTTPServerRunnerTLS.
+type HTTPServerOption func(*httpServerOptions)
+
+type httpServerOptions struct {
+	drainTimeout time.Duration
+	onServeError func(error)
+}
+
+// WithDrainTimeout limits how long the server is given to drain its
+// connections during shutdown. Once the timeout has passed, any remaining
+// connections are closed. A timeout of zero, the default, means wait for all
+// of the connections to drain.
+func WithDrainTimeout(timeout time.Duration) HTTPServerOption {
+	return func(o *httpServerOptions) {
+		o.drainTimeout = timeout
+	}
+}
+
+// WithServeErrorHandler sets the function which is called if the server stops
+// serving for any reason other than being shut down, e.g. because its address
+// is already in use. By default the error is only logged, with the Logger set
+// by SetLogger, since the server can't tell which await is running it. To shut
+// down gracefully rather than carrying on without the server, cancel the await
+// which runs it:
+//
+//	r := rununtil.New()
+//	err := r.Await(rununtil.HTTPServerRunner(srv, rununtil.WithServeErrorHandler(func(error) {
+//		r.Cancel()
+//	})))
+func WithServeErrorHandler(handler func(error)) HTTPServerOption {
+	return func(o *httpServerOptions) {
+		o.onServeError = handler
+	}
+}
+
+// HTTPServerRunner returns a RunnerFunc which runs srv.ListenAndServe in a go
+// routine and whose ShutdownFunc gracefully shuts the server down. The
+// http.ErrServerClosed that ListenAndServe returns once the server has been
+// shut down is treated as a clean exit.
+func HTTPServerRunner(srv *http.Server, opts ...HTTPServerOption) RunnerFunc {
+	return httpServerRunner(srv, srv.ListenAndServe, opts)
+}
+
+// HTTPServerRunnerTLS is the same as HTTPServerRunner, except that it runs
+// srv.ListenAndServeTLS with the certificate and key files.
+func HTTPServerRunnerTLS(srv *http.Server, certFile, keyFile string, opts ...HTTPServerOption) RunnerFunc {
+	return httpServerRunner(srv, func() error {
+		return srv.ListenAndServeTLS(certFile, keyFile)
+	}, opts)
+}
+
+// HTTPServerDeadlineRunner is the same as HTTPServerRunner, except that it
+// returns a DeadlineRunnerFunc whose CtxShutdownFunc passes the shutdown
+// deadline context to srv.Shutdown, so that draining the connections fits
+// within the budget given to AwaitKillSignalsDeadline. If WithDrainTimeout has
+// also been given, whichever of the two comes first applies.
+func HTTPServerDeadlineRunner(srv *http.Server, opts ...HTTPServerOption) DeadlineRunnerFunc {
+	return httpServerDeadlineRunner(srv, srv.ListenAndServe, opts)
+}
+
+// HTTPServerDeadlineRunnerTLS is the same as HTTPServerDeadlineRunner, except
+// that it runs srv.ListenAndServeTLS with the certificate and key files.
+func HTTPServerDeadlineRunnerTLS(srv *http.Server, certFile, keyFile string, opts ...HTTPServerOption) DeadlineRunnerFunc {
+	return httpServerDeadlineRunner(srv, func() error {
+		return srv.ListenAndServeTLS(certFile, keyFile)
+	}, opts)
+}
+
+func httpServerRunner(srv *http.Server, serve func() error, opts []HTTPServerOption) RunnerFunc {
+	start := httpServerDeadlineRunner(srv, serve, opts)
+	return func() ShutdownFunc {
+		shutdown := start()
+		return func() {
+			shutdown(context.Background())
+		}
+	}
+}
+
+func httpServerDeadlineRunner(srv *http.Server, serve func() error, opts []HTTPServerOption) DeadlineRunnerFunc {
+	o := httpServerOptions{onServeError: func(err error) {
+		getDefaultLogger().Errorf("http server on %s stopped serving: %v", srv.Addr, err)
+	}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return func() CtxShutdownFunc {
+		go func() {
+			if err := serve(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				o.onServeError(err)
+			}
+		}()
+
+		return func(ctx context.Context) {
+			if o.drainTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, o.drainTimeout)
+				defer cancel()
+			}
+			if err := srv.Shutdown(ctx); err != nil {
+				// the connections didn't drain in time, so force them closed
+				_ = srv.Close()
+			}
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package rununtil_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+// helperFreeAddr returns an address which nothing is currently listening on.
+func helperFreeAddr(t *testing.T) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer lis.Close()
+	return lis.Addr().String()
+}
+
+func TestHTTPServerRunner(t *testing.T) {
+	addr := helperFreeAddr(t)
+	srv := &http.Server{
+		Addr: addr,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	}
+	serveErrors := make(chan error, 1)
+	runner := rununtil.HTTPServerRunner(srv, rununtil.WithServeErrorHandler(func(err error) {
+		serveErrors <- err
+	}))
+
+	shutdown := runner()
+	if !helperWaitFor(func() bool {
+		resp, err := http.Get("http://" + addr)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusNoContent
+	}) {
+		t.Fatal("expected the server to be serving")
+	}
+
+	shutdown()
+	if _, err := http.Get("http://" + addr); err == nil {
+		t.Fatal("expected the server to have been shut down")
+	}
+	select {
+	case err := <-serveErrors:
+		t.Fatalf("expected shutting down not to be a serve error, got: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestHTTPServerRunner_ServeError(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer lis.Close()
+
+	serveErrors := make(chan error, 1)
+	srv := &http.Server{Addr: lis.Addr().String()}
+	runner := rununtil.HTTPServerRunner(srv, rununtil.WithServeErrorHandler(func(err error) {
+		serveErrors <- err
+	}))
+
+	shutdown := runner()
+	defer shutdown()
+	select {
+	case err := <-serveErrors:
+		if errors.Is(err, http.ErrServerClosed) {
+			t.Fatalf("expected an address in use error, got: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the serve error handler to have been called")
+	}
+}
+
+func TestHTTPServerRunner_ServeErrorLoggedByDefault(t *testing.T) {
+	logger := &fakeLogger{}
+	rununtil.SetLogger(logger)
+	defer rununtil.SetLogger(nil)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer lis.Close()
+
+	srv := &http.Server{Addr: lis.Addr().String()}
+	done := make(chan struct{})
+	go func() {
+		rununtil.AwaitKillSignals([]os.Signal{syscall.SIGINT}, rununtil.HTTPServerRunner(srv))
+		close(done)
+	}()
+	if !helperWaitFor(func() bool { return logger.contains("http server on " + srv.Addr + " stopped serving") }) {
+		t.Fatalf("expected the serve error to have been logged, got: %v", logger.lines)
+	}
+	if rununtil.ShuttingDown() {
+		t.Fatal("expected the serve error not to have cancelled the await")
+	}
+	helperCancelUntilDone(t, done)
+}
+
+func TestHTTPServerRunnerTLS_ServeError(t *testing.T) {
+	serveErrors := make(chan error, 1)
+	srv := &http.Server{Addr: helperFreeAddr(t)}
+	runner := rununtil.HTTPServerRunnerTLS(srv, "missing.crt", "missing.key", rununtil.WithServeErrorHandler(func(err error) {
+		serveErrors <- err
+	}))
+
+	shutdown := runner()
+	defer shutdown()
+	select {
+	case <-serveErrors:
+	case <-time.After(time.Second):
+		t.Fatal("expected the serve error handler to have been called for the missing certificate")
+	}
+}
+
+// helperHangingServer returns a server whose handler doesn't return until the
+// test has finished, so that it can't drain its connections, along with a
+// function which makes a request and waits until it is being handled.
+func helperHangingServer(t *testing.T) (*http.Server, func()) {
+	t.Helper()
+	handling := make(chan struct{})
+	hang := make(chan struct{})
+	t.Cleanup(func() { close(hang) })
+	var once sync.Once
+	srv := &http.Server{
+		Addr: helperFreeAddr(t),
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			once.Do(func() { close(handling) })
+			<-hang
+		}),
+	}
+
+	request := func() {
+		go func() {
+			for {
+				resp, err := http.Get("http://" + srv.Addr)
+				if err == nil {
+					resp.Body.Close()
+					return
+				}
+				select {
+				case <-handling:
+					return
+				case <-time.After(time.Millisecond):
+				}
+			}
+		}()
+		<-handling
+	}
+	return srv, request
+}
+
+func TestHTTPServerRunner_WithDrainTimeout(t *testing.T) {
+	srv, request := helperHangingServer(t)
+	runner := rununtil.HTTPServerRunner(srv, rununtil.WithDrainTimeout(20*time.Millisecond))
+
+	shutdown := runner()
+	request()
+
+	finished := make(chan struct{})
+	go func() {
+		shutdown()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("expected the shutdown to give up draining after the timeout")
+	}
+}
+
+func TestHTTPServerDeadlineRunner(t *testing.T) {
+	srv, request := helperHangingServer(t)
+	runner := rununtil.HTTPServerDeadlineRunner(srv)
+
+	shutdown := runner()
+	request()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	finished := make(chan struct{})
+	go func() {
+		shutdown(ctx)
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("expected the shutdown to give up draining once the deadline had passed")
+	}
+	if _, err := http.Get("http://" + srv.Addr); err == nil {
+		t.Fatal("expected the server to have been shut down")
+	}
+}
+
+func TestHTTPServerDeadlineRunner_WithDrainTimeout(t *testing.T) {
+	srv, request := helperHangingServer(t)
+	runner := rununtil.HTTPServerDeadlineRunner(srv, rununtil.WithDrainTimeout(20*time.Millisecond))
+
+	shutdown := runner()
+	request()
+
+	finished := make(chan struct{})
+	go func() {
+		shutdown(context.Background())
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("expected the drain timeout to apply when it comes before the deadline")
+	}
+}
+
+func TestHTTPServerDeadlineRunnerTLS_ServeError(t *testing.T) {
+	serveErrors := make(chan error, 1)
+	srv := &http.Server{Addr: helperFreeAddr(t)}
+	runner := rununtil.HTTPServerDeadlineRunnerTLS(srv, "missing.crt", "missing.key", rununtil.WithServeErrorHandler(func(err error) {
+		serveErrors <- err
+	}))
+
+	shutdown := runner()
+	defer shutdown(context.Background())
+	select {
+	case <-serveErrors:
+	case <-time.After(time.Second):
+		t.Fatal("expected the serve error handler to have been called for the missing certificate")
+	}
+}
@@ -0,0 +1,19 @@
+package rununtil
+
+import "os"
+
+// WithIgnoredSignals ignores the signals for as long as the await is running,
+// including during shutdown, and then restores their default behaviour. Unlike
+// the reload signals of AwaitKillSignalsWithReload they have no effect at all,
+// not even a log line, e.g. so that an accidental SIGHUP from a terminal
+// hanging up does nothing:
+//
+//	r := rununtil.New(rununtil.WithIgnoredSignals(syscall.SIGHUP))
+//
+// The ignored signals should not also be kill signals. They are not ignored if
+// the signals come from WithSignalSource, since os/signal is not used then.
+func WithIgnoredSignals(signals ...os.Signal) Option {
+	return func(o *options) {
+		o.ignored = append(o.ignored, signals...)
+	}
+}
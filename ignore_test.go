@@ -0,0 +1,45 @@
+package rununtil_test
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRununtilWithIgnoredSignals(t *testing.T) {
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("unexpected error occurred: %v", err)
+	}
+	r := rununtil.New(rununtil.WithIgnoredSignals(syscall.SIGHUP))
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await()
+	}()
+	if !helperWaitFor(func() bool { return r.NumAwaiting() > 0 }) {
+		t.Fatal("expected the await to have started")
+	}
+	if !signal.Ignored(syscall.SIGHUP) {
+		t.Fatal("expected SIGHUP to be ignored while awaiting")
+	}
+	// SIGHUP would kill the tests if it weren't being ignored
+	if err := p.Signal(syscall.SIGHUP); err != nil {
+		t.Fatalf("unexpected error occurred: %v", err)
+	}
+	select {
+	case err := <-errChan:
+		t.Fatalf("expected the ignored signal to have no effect, got: %v", err)
+	default:
+	}
+
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if signal.Ignored(syscall.SIGHUP) {
+		t.Fatal("expected SIGHUP to no longer be ignored once the await had finished")
+	}
+}
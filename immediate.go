@@ -0,0 +1,58 @@
+package rununtil
+
+import (
+	"os"
+	"syscall"
+)
+
+// WithImmediateSignals leaves the signals to their default behaviour, rather
+// than gracefully shutting down after them, e.g. so that SIGQUIT still dumps
+// the go routines and crashes the process without any of the shutdown
+// functions getting in the way:
+//
+//	rununtil.New(rununtil.WithCatchAllFatalSignals(), rununtil.WithImmediateSignals(syscall.SIGQUIT))
+//
+// The immediate signals are never listened for, even if they are kill
+// signals, are caught by WithCatchAllFatalSignals or have actions, and an
+// await which has no kill signals listens for every signal but them. With
+// WithSignalSource the immediate signals never stop the await.
+func WithImmediateSignals(signals ...os.Signal) Option {
+	return func(o *options) {
+		o.immediate = append(o.immediate, signals...)
+	}
+}
+
+// withoutImmediate removes the immediate signals from the kill signals and
+// the actions, so that they aren't listened for.
+func withoutImmediate(killSignals []os.Signal, opts options) ([]os.Signal, map[os.Signal][]func()) {
+	var kept []os.Signal
+	for _, sig := range killSignals {
+		if !containsSignal(opts.immediate, sig) {
+			kept = append(kept, sig)
+		}
+	}
+	actions := make(map[os.Signal][]func(), len(opts.actions))
+	for sig, sigActions := range opts.actions {
+		if !containsSignal(opts.immediate, sig) {
+			actions[sig] = sigActions
+		}
+	}
+	return kept, actions
+}
+
+// numSignals is one more than the highest signal number, of any platform,
+// which is listened for instead of every signal. Anything beyond it doesn't
+// exist, and os/signal ignores the signals which don't exist on the platform.
+const numSignals = 65
+
+// allSignalsExcept returns every signal other than the immediate ones, for an
+// await which has no kill signals and so would otherwise listen for them all.
+func allSignalsExcept(immediate []os.Signal) []os.Signal {
+	var signals []os.Signal
+	for i := 1; i < numSignals; i++ {
+		if sig := syscall.Signal(i); !containsSignal(immediate, sig) {
+			signals = append(signals, sig)
+		}
+	}
+	return signals
+}
@@ -0,0 +1,136 @@
+package rununtil_test
+
+import (
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+// helperNotified awaits with the options until cancelled, returning every
+// signal that it listened for.
+func helperNotified(t *testing.T, opts ...rununtil.Option) [][]os.Signal {
+	t.Helper()
+	var mux sync.Mutex
+	var notified [][]os.Signal
+	defer rununtil.SetSignalNotify(func(c chan<- os.Signal, sig ...os.Signal) {
+		mux.Lock()
+		defer mux.Unlock()
+		notified = append(notified, sig)
+	})()
+
+	r := rununtil.New(opts...)
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await()
+	}()
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mux.Lock()
+	defer mux.Unlock()
+	return notified
+}
+
+func TestRununtilWithImmediateSignals(t *testing.T) {
+	notified := helperNotified(t,
+		rununtil.WithSignals(syscall.SIGTERM, syscall.SIGQUIT),
+		rununtil.WithCatchAllFatalSignals(),
+		rununtil.WithImmediateSignals(syscall.SIGQUIT),
+	)
+
+	if len(notified) != 1 {
+		t.Fatalf("expected to have listened once, got: %v", notified)
+	}
+	if !containsSignal(notified[0], syscall.SIGTERM) {
+		t.Fatalf("expected to have listened for SIGTERM, got: %v", notified[0])
+	}
+	if containsSignal(notified[0], syscall.SIGQUIT) {
+		t.Fatalf("expected not to have listened for SIGQUIT, got: %v", notified[0])
+	}
+}
+
+func TestRununtilWithImmediateSignals_AllImmediate(t *testing.T) {
+	notified := helperNotified(t, rununtil.WithSignals(syscall.SIGQUIT), rununtil.WithImmediateSignals(syscall.SIGQUIT))
+
+	if len(notified) != 0 {
+		t.Fatalf("expected not to have listened for any signals, got: %v", notified)
+	}
+}
+
+func TestRununtilWithImmediateSignals_ListenAll(t *testing.T) {
+	notified := helperNotified(t, rununtil.WithSignals(), rununtil.WithImmediateSignals(syscall.SIGQUIT))
+
+	if len(notified) != 1 {
+		t.Fatalf("expected to have listened once, got: %v", notified)
+	}
+	if len(notified[0]) == 0 {
+		t.Fatal("expected to have listened for every signal but SIGQUIT, rather than for every signal")
+	}
+	if !containsSignal(notified[0], syscall.SIGTERM) || !containsSignal(notified[0], syscall.SIGHUP) {
+		t.Fatalf("expected to have listened for the other signals, got: %v", notified[0])
+	}
+	if containsSignal(notified[0], syscall.SIGQUIT) {
+		t.Fatalf("expected not to have listened for SIGQUIT, got: %v", notified[0])
+	}
+}
+
+func TestRununtilWithImmediateSignals_ListenAllSignalSource(t *testing.T) {
+	signals := make(chan os.Signal)
+	r := rununtil.New(
+		rununtil.WithSignals(),
+		rununtil.WithImmediateSignals(syscall.SIGQUIT),
+		rununtil.WithSignalSource(signals),
+	)
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await()
+	}()
+	signals <- syscall.SIGQUIT
+	select {
+	case err := <-errChan:
+		t.Fatalf("expected the immediate signal not to have stopped the await, got: %v", err)
+	default:
+	}
+	signals <- syscall.SIGHUP
+	if err := <-errChan; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRununtilWithImmediateSignals_SignalSource(t *testing.T) {
+	signals := make(chan os.Signal)
+	r := rununtil.New(
+		rununtil.WithSignals(syscall.SIGTERM, syscall.SIGQUIT),
+		rununtil.WithImmediateSignals(syscall.SIGQUIT),
+		rununtil.WithSignalSource(signals),
+	)
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await()
+	}()
+	signals <- syscall.SIGQUIT
+	select {
+	case err := <-errChan:
+		t.Fatalf("expected the immediate signal not to have stopped the await, got: %v", err)
+	default:
+	}
+	signals <- syscall.SIGTERM
+	if err := <-errChan; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// containsSignal reports whether sig is one of the signals.
+func containsSignal(signals []os.Signal, sig os.Signal) bool {
+	for _, s := range signals {
+		if s == sig {
+			return true
+		}
+	}
+	return false
+}
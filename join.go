@@ -0,0 +1,57 @@
+package rununtil
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// JoinableRunnerFunc is a variant of RunnerFunc which also returns a channel
+// that is closed once all of the go routines it set off have exited, to be run
+// by AwaitKillSignalsJoin. This gives the await a join point, since a
+// ShutdownFunc such as http.Server.Shutdown can return while those go routines
+// are still running. A nil channel means there is nothing to wait for, and a
+// nil ShutdownFunc means the go routines stop by themselves, so the await
+// only waits for the channel.
+type JoinableRunnerFunc func() (ShutdownFunc, <-chan struct{})
+
+// asStarter converts the JoinableRunnerFunc into a starter whose shutdown
+// waits for the stopped channel to be closed, or for the shutdown deadline to
+// pass.
+func (runner JoinableRunnerFunc) asStarter() starter {
+	return func(context.Context) (stopFunc, error) {
+		shutdown, stopped := runner()
+		if shutdown == nil && stopped == nil {
+			return nil, nil
+		}
+		return func(ctx context.Context) error {
+			if shutdown != nil {
+				shutdown()
+			}
+			if stopped == nil {
+				return nil
+			}
+			select {
+			case <-stopped:
+				return nil
+			case <-ctx.Done():
+				return fmt.Errorf("%w: %w", ErrShutdownDeadline, ctx.Err())
+			}
+		}, nil
+	}
+}
+
+// AwaitKillSignalsJoin runs the provided JoinableRunnerFuncs until the
+// specified signals have been received, at which point it executes each of
+// their ShutdownFuncs and waits for their go routines to exit, before moving on
+// to the next:
+//
+//	err := rununtil.AwaitKillSignalsJoin(25*time.Second, signals, worker)
+//
+// The waits share a single context which times out after total, and when it
+// passes AwaitKillSignalsJoin returns an error wrapping ErrShutdownDeadline. A
+// total of zero means wait forever.
+func AwaitKillSignalsJoin(total time.Duration, signals []os.Signal, runnerFuncs ...JoinableRunnerFunc) error {
+	return awaitKillSignals(signals, starters(runnerFuncs), newOptions([]Option{WithShutdownDeadline(total)}))
+}
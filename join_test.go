@@ -0,0 +1,97 @@
+package rununtil_test
+
+import (
+	"errors"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRununtilAwaitKillSignalsJoin(t *testing.T) {
+	var workerExited atomic.Bool
+	worker := rununtil.JoinableRunnerFunc(func() (rununtil.ShutdownFunc, <-chan struct{}) {
+		quit := make(chan struct{})
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			<-quit
+			// finish off the work in flight after being told to stop
+			time.Sleep(10 * time.Millisecond)
+			workerExited.Store(true)
+		}()
+		return func() { close(quit) }, stopped
+	})
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- rununtil.AwaitKillSignalsJoin(time.Minute, []os.Signal{syscall.SIGINT}, worker)
+	}()
+	if err := helperCancelUntilDone(t, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !workerExited.Load() {
+		t.Fatal("expected the await to wait for the worker to exit")
+	}
+}
+
+func TestRununtilAwaitKillSignalsJoin_NilChannel(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	worker := rununtil.JoinableRunnerFunc(func() (rununtil.ShutdownFunc, <-chan struct{}) {
+		return func() { hasBeenShutdown.Store(true) }, nil
+	})
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- rununtil.AwaitKillSignalsJoin(0, []os.Signal{syscall.SIGINT}, worker)
+	}()
+	if err := helperCancelUntilDone(t, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the runner to have been shutdown")
+	}
+}
+
+func TestRununtilAwaitKillSignalsJoin_NilShutdownFunc(t *testing.T) {
+	var workerExited atomic.Bool
+	worker := rununtil.JoinableRunnerFunc(func() (rununtil.ShutdownFunc, <-chan struct{}) {
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			// the work stops by itself, without being told to
+			time.Sleep(10 * time.Millisecond)
+			workerExited.Store(true)
+		}()
+		return nil, stopped
+	})
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- rununtil.AwaitKillSignalsJoin(time.Minute, []os.Signal{syscall.SIGINT}, worker)
+	}()
+	if err := helperCancelUntilDone(t, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !workerExited.Load() {
+		t.Fatal("expected the await to wait for the worker to exit")
+	}
+}
+
+func TestRununtilAwaitKillSignalsJoin_Deadline(t *testing.T) {
+	never := make(chan struct{})
+	worker := rununtil.JoinableRunnerFunc(func() (rununtil.ShutdownFunc, <-chan struct{}) {
+		return func() {}, never
+	})
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- rununtil.AwaitKillSignalsJoin(10*time.Millisecond, []os.Signal{syscall.SIGINT}, worker)
+	}()
+	if err := helperCancelUntilDone(t, errChan); !errors.Is(err, rununtil.ErrShutdownDeadline) {
+		t.Fatalf("expected a shutdown deadline error, got: %v", err)
+	}
+}
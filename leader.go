@@ -0,0 +1,50 @@
+package rununtil
+
+import (
+	"context"
+	"fmt"
+)
+
+// WithLeaderRelease calls release as soon as the await has been told to stop,
+// before the WithPreShutdown hooks, the WithLameDuckDelay and any of the
+// shutdown functions, so that a replica which is the leader, e.g. of a
+// Kubernetes leader election, gives up its leadership for a standby to take
+// over as soon as possible, rather than only once its workers have stopped:
+//
+//	rununtil.WithLeaderRelease(func(ctx context.Context) error {
+//		return lock.Release(ctx)
+//	})
+//
+// The context is done once the WithShutdownDeadline has passed since release
+// was called, and release is given the WithShutdownTimeout in the same way as a shutdown function. If
+// it fails the rest of the shutdown still goes ahead, and its error is
+// returned along with those of the shutdown functions, and reported as the
+// ShutdownReport's LeaderReleaseErr.
+func WithLeaderRelease(release func(ctx context.Context) error) Option {
+	return func(o *options) {
+		o.leaderRelease = release
+	}
+}
+
+// releaseLeadership calls the WithLeaderRelease function, if there is one and
+// it hasn't already been called, keeping its error in leaderErr.
+func (s *session) releaseLeadership() {
+	if s.opts.leaderRelease == nil || s.leaderReleased {
+		return
+	}
+	s.leaderReleased = true
+	ctx := context.WithValue(context.Background(), reasonKey{}, s.reason())
+	if s.opts.shutdownDeadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.opts.shutdownDeadline)
+		defer cancel()
+	}
+	s.opts.logger.Infof("releasing leadership")
+	err := runShutdown(ctx, s.opts.leaderRelease, s.opts.shutdownTimeout, s.opts.clock)
+	if s.opts.reporter != nil {
+		s.opts.reporter.releasedLeadership(err)
+	}
+	if err != nil {
+		s.leaderErr = fmt.Errorf("leader release: %w", err)
+	}
+}
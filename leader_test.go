@@ -0,0 +1,109 @@
+package rununtil_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRununtilWithLeaderRelease(t *testing.T) {
+	rec := &orderRecorder{}
+	r := rununtil.New(rununtil.WithLeaderRelease(func(context.Context) error {
+		rec.mux.Lock()
+		defer rec.mux.Unlock()
+		rec.shutdown = append(rec.shutdown, "leader")
+		return nil
+	}))
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(rec.runner("first"), rec.runner("second"))
+	}()
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"leader", "second", "first"}; !reflect.DeepEqual(rec.shutdown, expected) {
+		t.Fatalf("expected leadership to be released first %v, got: %v", expected, rec.shutdown)
+	}
+}
+
+func TestRununtilWithLeaderRelease_BeforeLameDuckDelay(t *testing.T) {
+	events := make(chan string, 3)
+	delay := 50 * time.Millisecond
+	var releasedAt, shutdownAt time.Time
+	r := rununtil.New(
+		rununtil.WithPreShutdown(func() { events <- "pre-shutdown" }),
+		rununtil.WithLameDuckDelay(delay),
+		rununtil.WithLeaderRelease(func(context.Context) error {
+			releasedAt = time.Now()
+			events <- "leader"
+			return nil
+		}),
+	)
+	runner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		return func() {
+			shutdownAt = time.Now()
+			events <- "shutdown"
+		}
+	})
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(runner)
+	}()
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{"leader", "pre-shutdown", "shutdown"} {
+		if event := <-events; event != expected {
+			t.Fatalf("expected %q, got: %q", expected, event)
+		}
+	}
+	if elapsed := shutdownAt.Sub(releasedAt); elapsed < delay {
+		t.Fatalf("expected the leadership to be released at least %v before the shutdown, got: %v", delay, elapsed)
+	}
+}
+
+func TestRununtilWithLeaderRelease_Fails(t *testing.T) {
+	releaseErr := errors.New("lease lost")
+	signals := make(chan os.Signal)
+	var deadline time.Time
+	opts := []rununtil.Option{
+		rununtil.WithSignalSource(signals),
+		rununtil.WithShutdownDeadline(time.Minute),
+		rununtil.WithLeaderRelease(func(ctx context.Context) error {
+			deadline, _ = ctx.Deadline()
+			return releaseErr
+		}),
+	}
+	var hasBeenShutdown atomic.Bool
+
+	results := make(chan reportResult)
+	go func() {
+		report, err := rununtil.AwaitKillSignalsResult([]os.Signal{syscall.SIGTERM}, opts, helperMakeFakeRunner(&hasBeenShutdown))
+		results <- reportResult{report: report, err: err}
+	}()
+	signals <- syscall.SIGTERM
+	result := <-results
+
+	if !errors.Is(result.err, releaseErr) {
+		t.Fatalf("expected the release error to have been returned, got: %v", result.err)
+	}
+	if !errors.Is(result.report.LeaderReleaseErr, releaseErr) {
+		t.Fatalf("expected the release error to have been reported, got: %v", result.report.LeaderReleaseErr)
+	}
+	if deadline.IsZero() {
+		t.Fatal("expected the release to have been given the shutdown deadline")
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the shutdown to have gone ahead")
+	}
+}
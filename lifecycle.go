@@ -0,0 +1,31 @@
+package rununtil
+
+import (
+	"context"
+	"os"
+)
+
+// NewLifecycle starts listening for the signals, SIGINT and SIGTERM if none
+// are given, and returns the context of the process' lifecycle along with a
+// function which awaits them. The context is cancelled as soon as one of the
+// signals has been received, or CancelAll has been called, and before any of
+// the ShutdownFuncs are executed. This means the context can be wired into
+// code that isn't a runner, such as request handlers that should reject new
+// work during shutdown, before the runners are started:
+//
+//	ctx, await := rununtil.NewLifecycle()
+//	handler := NewHandler(ctx)
+//	await(NewRunner(handler))
+//
+// The await function blocks in the same way as AwaitKillSignals, and must only
+// be called once. A signal which arrives before it is called is not lost, but
+// shutdown only begins once it has been called.
+func NewLifecycle(signals ...os.Signal) (ctx context.Context, await func(runnerFuncs ...RunnerFunc)) {
+	if len(signals) == 0 {
+		signals = defaultSignals()
+	}
+	s := defaultRunner.newSession(signals, newOptions(nil))
+	return s.ctx, func(runnerFuncs ...RunnerFunc) {
+		repanic(s.run(starters(runnerFuncs)))
+	}
+}
@@ -0,0 +1,56 @@
+package rununtil_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRununtilNewLifecycle(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	ctx, await := rununtil.NewLifecycle()
+	if ctx.Err() != nil {
+		t.Fatalf("expected the context not to have been cancelled yet, got: %v", ctx.Err())
+	}
+
+	var cancelledBeforeShutdown atomic.Bool
+	runner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		return func() {
+			cancelledBeforeShutdown.Store(ctx.Err() != nil)
+			hasBeenShutdown.Store(true)
+		}
+	})
+	done := make(chan struct{})
+	go func() {
+		await(runner)
+		close(done)
+	}()
+	helperCancelUntilDone(t, done)
+
+	if !errors.Is(ctx.Err(), context.Canceled) {
+		t.Fatalf("expected the context to have been cancelled, got: %v", ctx.Err())
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the shutdown function to have been called")
+	}
+	if !cancelledBeforeShutdown.Load() {
+		t.Fatal("expected the context to be cancelled before the shutdown function was called")
+	}
+}
+
+func TestRununtilNewLifecycle_CancelledBeforeAwait(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	ctx, await := rununtil.NewLifecycle()
+	rununtil.CancelAll()
+
+	await(helperMakeFakeRunner(&hasBeenShutdown))
+	if ctx.Err() == nil {
+		t.Fatal("expected the context to have been cancelled")
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the shutdown function to have been called")
+	}
+}
@@ -0,0 +1,23 @@
+package rununtil
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrMaxLifetime is the cause of the context given to the runners when the
+// await was stopped because its WithMaxLifetime had passed.
+var ErrMaxLifetime = errors.New("maximum lifetime reached")
+
+// WithMaxLifetime begins graceful shutdown once the runners have been running
+// for the lifetime, exactly as if a kill signal had been received, so that
+// short lived batch services and CI jobs are guaranteed to exit. The lifetime
+// is measured from once all of the runners have started, so a slow start
+// doesn't eat into it. A kill signal received before then still shuts them
+// down straight away. A lifetime of zero, the default, runs them until a kill
+// signal is received.
+func WithMaxLifetime(lifetime time.Duration) Option {
+	return func(o *options) {
+		o.maxLifetime = lifetime
+	}
+}
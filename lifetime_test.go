@@ -0,0 +1,81 @@
+package rununtil_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRununtilWithMaxLifetime(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	clock := newFakeClock()
+	r := rununtil.New(rununtil.WithClock(clock), rununtil.WithMaxLifetime(time.Hour))
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(helperMakeFakeRunner(&hasBeenShutdown))
+	}()
+	<-clock.waiting
+
+	clock.advance(time.Minute)
+	select {
+	case err := <-errChan:
+		t.Fatalf("expected the await to still be running, got: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	clock.advance(time.Hour)
+	if err := <-errChan; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the runner to have been shutdown")
+	}
+}
+
+func TestRununtilWithMaxLifetime_SlowStart(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	clock := newFakeClock()
+	r := rununtil.New(rununtil.WithClock(clock), rununtil.WithMaxLifetime(time.Hour))
+	fakeRunner := helperMakeFakeRunner(&hasBeenShutdown)
+	slowRunner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		clock.advance(2 * time.Hour)
+		return fakeRunner()
+	})
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(slowRunner)
+	}()
+	<-clock.waiting
+
+	select {
+	case err := <-errChan:
+		t.Fatalf("expected the time taken to start not to count towards the lifetime, got: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	clock.advance(time.Hour)
+	if err := <-errChan; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the runner to have been shutdown")
+	}
+}
+
+func TestRununtilWithMaxLifetime_Cancelled(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	r := rununtil.New(rununtil.WithMaxLifetime(time.Hour))
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(helperMakeFakeRunner(&hasBeenShutdown))
+	}()
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the runner to have been shutdown before its lifetime had passed")
+	}
+}
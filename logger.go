@@ -0,0 +1,56 @@
+package rununtil
+
+import "sync"
+
+// Logger is used to log the lifecycle events of an await, for example:
+//
+//	received signal interrupt
+//	starting shutdown of 3 runners
+//	shutdown complete in 42ms
+//
+// It is small enough that most logging libraries can be adapted to it with a
+// couple of one line methods.
+type Logger interface {
+	Infof(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// nopLogger is the default Logger, which doesn't log anything.
+type nopLogger struct{}
+
+func (nopLogger) Infof(format string, args ...interface{})  {}
+func (nopLogger) Errorf(format string, args ...interface{}) {}
+
+// defaultLogger is the Logger which is used when WithLogger isn't given.
+var defaultLogger struct {
+	mux    sync.RWMutex
+	logger Logger
+}
+
+// SetLogger sets the Logger which is used by the package level functions, such
+// as AwaitKillSignal and Main, and by Runners that haven't been given one with
+// WithLogger. Setting it to nil stops anything from being logged, which is the
+// default.
+func SetLogger(logger Logger) {
+	defaultLogger.mux.Lock()
+	defer defaultLogger.mux.Unlock()
+	defaultLogger.logger = logger
+}
+
+// DefaultLogger returns the Logger set by SetLogger, or one which doesn't log
+// anything if it hasn't been set, for the runners of other packages to log
+// with, such as those of rununtilgrpc.
+func DefaultLogger() Logger {
+	return getDefaultLogger()
+}
+
+// getDefaultLogger returns the Logger set with SetLogger, or one which doesn't
+// log anything.
+func getDefaultLogger() Logger {
+	defaultLogger.mux.RLock()
+	defer defaultLogger.mux.RUnlock()
+	if defaultLogger.logger == nil {
+		return nopLogger{}
+	}
+	return defaultLogger.logger
+}
@@ -0,0 +1,126 @@
+package rununtil_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+// fakeLogger records everything that is logged to it.
+type fakeLogger struct {
+	mux   sync.Mutex
+	lines []string
+}
+
+func (l *fakeLogger) Infof(format string, args ...interface{}) {
+	l.log("INFO: "+format, args...)
+}
+
+func (l *fakeLogger) Errorf(format string, args ...interface{}) {
+	l.log("ERROR: "+format, args...)
+}
+
+func (l *fakeLogger) log(format string, args ...interface{}) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+}
+
+func (l *fakeLogger) contains(substr string) bool {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	for _, line := range l.lines {
+		if strings.Contains(line, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRununtilWithLogger(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	logger := &fakeLogger{}
+	r := rununtil.New(rununtil.WithLogger(logger))
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(helperMakeFakeRunner(&hasBeenShutdown), helperMakeFakeRunner(&hasBeenShutdown))
+	}()
+	helperKeepCancelling(t, r.Cancel, errChan)
+
+	for _, expected := range []string{
+		"INFO: await cancelled",
+		"INFO: starting shutdown of 2 runners",
+		"INFO: shutdown complete in ",
+	} {
+		if !logger.contains(expected) {
+			t.Fatalf("expected %q to have been logged, got: %v", expected, logger.lines)
+		}
+	}
+}
+
+func TestRununtilWithLogger_Signal(t *testing.T) {
+	var sentSignal atomic.Bool
+	logger := &fakeLogger{}
+	r := rununtil.New(rununtil.WithLogger(logger), rununtil.WithSignals(syscall.SIGINT))
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("Unexpected error when finding process: %v", err)
+	}
+
+	go helperSendSignalWhenAwaiting(t, p, &sentSignal, syscall.SIGINT, time.Millisecond, r.NumAwaiting)
+	if err := r.Await(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !logger.contains("INFO: received signal interrupt") {
+		t.Fatalf("expected the signal to have been logged, got: %v", logger.lines)
+	}
+}
+
+func TestRununtilWithLogger_Panic(t *testing.T) {
+	logger := &fakeLogger{}
+	r := rununtil.New(rununtil.WithLogger(logger))
+
+	_ = r.Await(helperMakePanickingRunner("boom"))
+	if !logger.contains("ERROR: runner 0 (rununtil_test.helperMakePanickingRunner.func1) panicked: boom") {
+		t.Fatalf("expected the panic to have been logged, got: %v", logger.lines)
+	}
+}
+
+func TestRununtilSetLogger(t *testing.T) {
+	logger := &fakeLogger{}
+	rununtil.SetLogger(logger)
+	defer rununtil.SetLogger(nil)
+
+	done := make(chan struct{})
+	go func() {
+		rununtil.AwaitKillSignals([]os.Signal{syscall.SIGINT})
+		close(done)
+	}()
+	helperCancelUntilDone(t, done)
+	if !logger.contains("INFO: await cancelled") {
+		t.Fatalf("expected the package level await to have been logged, got: %v", logger.lines)
+	}
+}
+
+func TestRununtilDefaultLogger(t *testing.T) {
+	logger := &fakeLogger{}
+	rununtil.SetLogger(logger)
+	defer rununtil.SetLogger(nil)
+
+	rununtil.DefaultLogger().Errorf("failed to %s", "serve")
+	if !logger.contains("ERROR: failed to serve") {
+		t.Fatalf("expected the Logger set by SetLogger to be returned, got: %v", logger.lines)
+	}
+
+	rununtil.SetLogger(nil)
+	// the Logger which doesn't log anything must still be usable
+	rununtil.DefaultLogger().Infof("nothing")
+}
@@ -0,0 +1,97 @@
+package rununtil
+
+import (
+	"context"
+	"errors"
+	"os"
+)
+
+// exit is called by Main, and by WithForceQuitOnSecondSignal, to exit the
+// process, so that the tests can stop it from actually doing so.
+var exit = os.Exit
+
+// WithExitFunc sets the function which is called, instead of os.Exit, when the
+// process is forced to quit, e.g. by WithForceQuitOnSecondSignal. It lets
+// logs be flushed, or other cleanup run, before the real exit, and so it
+// should finish by calling os.Exit with the code:
+//
+//	rununtil.WithExitFunc(func(code int) {
+//		logger.Sync()
+//		os.Exit(code)
+//	})
+func WithExitFunc(fn func(code int)) Option {
+	return func(o *options) {
+		o.exit = fn
+	}
+}
+
+// exitProcess exits the process with the code, using the WithExitFunc if
+// there is one.
+func (o options) exitProcess(code int) {
+	if o.exit != nil {
+		o.exit(code)
+		return
+	}
+	exit(code)
+}
+
+// Main is a safe default entry point for a program's main function:
+//
+//	func main() {
+//		rununtil.Main(NewRunner(logger))
+//	}
+//
+// It runs the provided RunnerFuncs until a kill signal has been received, in
+// the same way as AwaitKillSignal, and then exits the process. If one of the
+// RunnerFuncs panics, either while it is starting or while it is shutting
+// down, the rest are still shut down, the panic is logged with the Logger set
+// by SetLogger and Main exits with 2, as for an unrecovered panic. Otherwise
+// it exits with 0.
+func Main(runnerFuncs ...RunnerFunc) {
+	opts := newOptions(nil)
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			opts.logger.Errorf("exiting after a panic: %v", newPanicError(recovered))
+			opts.exitProcess(2)
+		}
+	}()
+	if err := awaitKillSignals(opts.signals, starters(runnerFuncs), opts); err != nil {
+		opts.logger.Errorf("exiting after a panic: %v", err)
+		opts.exitProcess(2)
+		return
+	}
+	opts.exitProcess(0)
+}
+
+// ExitCodeFor returns the conventional exit code for an await which stopped
+// for the reason, so that shell scripts and orchestrators get predictable exit
+// codes from every service: 0 if it was gracefully shut down, whatever stopped
+// it, 2 if a runner panicked, as for an unrecovered panic, and 1 if it failed
+// for any other reason. A process which is forced to quit by
+// WithForceQuitOnSecondSignal exits with 130 straight away instead.
+func ExitCodeFor(reason TerminationReason) int {
+	var panicErr *PanicError
+	switch {
+	case errors.As(reason.Err, &panicErr):
+		return 2
+	case reason.Err != nil:
+		return 1
+	}
+	return 0
+}
+
+// RunMain is the same as Main, except that it exits the process with the
+// ExitCodeFor the reason that the await stopped:
+//
+//	func main() {
+//		rununtil.RunMain(NewRunner(logger))
+//	}
+func RunMain(runnerFuncs ...RunnerFunc) {
+	opts := newOptions(nil)
+	reason := awaitUntil(context.Background(), opts.signals, starters(runnerFuncs), opts)
+	code := ExitCodeFor(reason)
+	if reason.Err != nil {
+		opts.logger.Errorf("exiting with %d: %v", code, reason.Err)
+	}
+	opts.exitProcess(code)
+}
@@ -0,0 +1,105 @@
+package rununtil_test
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"syscall"
+	"testing"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+// helperCaptureExit stops Main from exiting the tests, and returns a channel
+// which receives the code that it would have exited with.
+func helperCaptureExit(t *testing.T) <-chan int {
+	codes := make(chan int, 1)
+	t.Cleanup(rununtil.SetExit(func(code int) {
+		codes <- code
+	}))
+	return codes
+}
+
+func TestRununtilMain(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	codes := helperCaptureExit(t)
+
+	go rununtil.Main(helperMakeFakeRunner(&hasBeenShutdown))
+	if code := helperCancelUntilDone(t, codes); code != 0 {
+		t.Fatalf("expected to exit with 0, got: %d", code)
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the shutdown function to have been called")
+	}
+}
+
+func TestRununtilMain_Panics(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	codes := helperCaptureExit(t)
+	logger := &fakeLogger{}
+	rununtil.SetLogger(logger)
+	defer rununtil.SetLogger(nil)
+
+	rununtil.Main(helperMakeFakeRunner(&hasBeenShutdown), helperMakePanickingRunner("boom"))
+	if code := <-codes; code != 2 {
+		t.Fatalf("expected to exit with 2, got: %d", code)
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the runner which had started to have been shutdown")
+	}
+	if !logger.contains("ERROR: exiting after a panic: runner panicked: boom") {
+		t.Fatalf("expected the panic to have been logged, got: %v", logger.lines)
+	}
+}
+
+func TestRununtilExitCodeFor(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		reason   rununtil.TerminationReason
+		expected int
+	}{
+		{"Signal", rununtil.TerminationReason{Kind: rununtil.ReasonSignal, Signal: syscall.SIGTERM}, 0},
+		{"Context", rununtil.TerminationReason{Kind: rununtil.ReasonContext}, 0},
+		{"CancelAll", rununtil.TerminationReason{Kind: rununtil.ReasonCancelAll}, 0},
+		{"Error", rununtil.TerminationReason{Kind: rununtil.ReasonSignal, Err: errors.New("failed")}, 1},
+		{"Panic", rununtil.TerminationReason{Err: fmt.Errorf("wrapped: %w", &rununtil.PanicError{Value: "boom"})}, 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if code := rununtil.ExitCodeFor(tc.reason); code != tc.expected {
+				t.Fatalf("expected exit code %d, got: %d", tc.expected, code)
+			}
+		})
+	}
+}
+
+func TestRununtilRunMain(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	codes := helperCaptureExit(t)
+
+	go rununtil.RunMain(helperMakeFakeRunner(&hasBeenShutdown))
+	if code := helperCancelUntilDone(t, codes); code != 0 {
+		t.Fatalf("expected to exit with 0, got: %d", code)
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the shutdown function to have been called")
+	}
+}
+
+func TestRununtilRunMain_Panics(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	codes := helperCaptureExit(t)
+	logger := &fakeLogger{}
+	rununtil.SetLogger(logger)
+	defer rununtil.SetLogger(nil)
+
+	rununtil.RunMain(helperMakeFakeRunner(&hasBeenShutdown), helperMakePanickingRunner("boom"))
+	if code := <-codes; code != 2 {
+		t.Fatalf("expected to exit with 2, got: %d", code)
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the runner which had started to have been shutdown")
+	}
+	if !logger.contains("ERROR: exiting with 2: runner panicked: boom") {
+		t.Fatalf("expected the panic to have been logged, got: %v", logger.lines)
+	}
+}
@@ -0,0 +1,76 @@
+package rununtil
+
+import (
+	"fmt"
+	"time"
+)
+
+// Metrics records metrics about an await, e.g. so that an alert can be raised
+// when shutdowns take longer than the grace period. It is small enough that
+// most metrics libraries can be adapted to it, for example with a Prometheus
+// histogram and a counter vector labelled by signal.
+type Metrics interface {
+	// ObserveShutdownDuration is called with how long the graceful shutdown
+	// took, once it has finished.
+	ObserveShutdownDuration(d time.Duration)
+	// IncSignalReceived is called with the name of each signal that is
+	// received, whether it is a kill signal or not.
+	IncSignalReceived(sig string)
+	// SetRunningRunners is called with the number of runners which have
+	// started and haven't yet been shut down, whenever it changes.
+	SetRunningRunners(n int)
+	// IncShutdownStarted is called each time a graceful shutdown begins.
+	IncShutdownStarted()
+	// SetPhase is called with each Phase of the await's lifecycle as it
+	// enters it.
+	SetPhase(phase Phase)
+}
+
+// Phase is a stage of an await's lifecycle, e.g. for a gauge which shows
+// where a process is during a deploy.
+type Phase int
+
+const (
+	// PhaseStarting means that the runners are being started.
+	PhaseStarting Phase = iota + 1
+	// PhaseRunning means that every runner has started, and the await is
+	// waiting for a kill signal.
+	PhaseRunning
+	// PhaseDraining means that shutdown has begun, including the
+	// pre-shutdown hooks and the lame duck delay.
+	PhaseDraining
+	// PhaseStopped means that shutdown has finished.
+	PhaseStopped
+)
+
+// String returns the name of the phase, e.g. for a metric label.
+func (p Phase) String() string {
+	switch p {
+	case PhaseStarting:
+		return "starting"
+	case PhaseRunning:
+		return "running"
+	case PhaseDraining:
+		return "draining"
+	case PhaseStopped:
+		return "stopped"
+	}
+	return fmt.Sprintf("phase %d", int(p))
+}
+
+// WithMetrics sets the Metrics which record metrics about the await. By
+// default no metrics are recorded.
+func WithMetrics(metrics Metrics) Option {
+	return func(o *options) {
+		o.metrics = metrics
+	}
+}
+
+// nopMetrics is the default Metrics, which doesn't record anything.
+type nopMetrics struct{}
+
+func (nopMetrics) ObserveShutdownDuration(d time.Duration) {}
+func (nopMetrics) IncSignalReceived(sig string)            {}
+func (nopMetrics) SetRunningRunners(n int)                 {}
+func (nopMetrics) IncShutdownStarted()                     {}
+func (nopMetrics) SetPhase(phase Phase)                    {}
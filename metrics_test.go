@@ -0,0 +1,143 @@
+package rununtil_test
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+// fakeMetrics records all of the metrics that it is given.
+type fakeMetrics struct {
+	mux       sync.Mutex
+	durations []time.Duration
+	signals   map[string]int
+	running   []int
+	shutdowns int
+	phases    []rununtil.Phase
+}
+
+func (m *fakeMetrics) ObserveShutdownDuration(d time.Duration) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.durations = append(m.durations, d)
+}
+
+func (m *fakeMetrics) IncSignalReceived(sig string) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if m.signals == nil {
+		m.signals = make(map[string]int)
+	}
+	m.signals[sig]++
+}
+
+func (m *fakeMetrics) SetRunningRunners(n int) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.running = append(m.running, n)
+}
+
+func (m *fakeMetrics) IncShutdownStarted() {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.shutdowns++
+}
+
+func (m *fakeMetrics) SetPhase(phase rununtil.Phase) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.phases = append(m.phases, phase)
+}
+
+// phase returns the phase that the await is currently in.
+func (m *fakeMetrics) phase() rununtil.Phase {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if len(m.phases) == 0 {
+		return 0
+	}
+	return m.phases[len(m.phases)-1]
+}
+
+func TestRununtilWithMetrics(t *testing.T) {
+	metrics := &fakeMetrics{}
+	delay := 10 * time.Millisecond
+	r := rununtil.New(rununtil.WithMetrics(metrics), rununtil.WithSignals(syscall.SIGINT))
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("Unexpected error when finding process: %v", err)
+	}
+
+	var sentSignal, hasBeenShutdown atomic.Bool
+	go helperSendSignalWhenAwaiting(t, p, &sentSignal, syscall.SIGINT, time.Millisecond, r.NumAwaiting)
+	if err := r.Await(helperMakeSlowRunner(delay, &hasBeenShutdown)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if n := metrics.signals["interrupt"]; n != 1 {
+		t.Fatalf("expected the signal to have been counted once, got: %v", metrics.signals)
+	}
+	if len(metrics.durations) != 1 || metrics.durations[0] < delay {
+		t.Fatalf("expected a shutdown duration of at least %v to have been observed, got: %v", delay, metrics.durations)
+	}
+}
+
+func TestRununtilWithMetrics_Lifecycle(t *testing.T) {
+	metrics := &fakeMetrics{}
+	signals := make(chan os.Signal)
+	r := rununtil.New(rununtil.WithMetrics(metrics), rununtil.WithSignalSource(signals))
+	var firstShutdown, secondShutdown atomic.Bool
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(helperMakeFakeRunner(&firstShutdown), helperMakeFakeRunner(&secondShutdown))
+	}()
+	if !helperWaitFor(func() bool { return metrics.phase() == rununtil.PhaseRunning }) {
+		t.Fatalf("expected the await to be running, got: %v", metrics.phase())
+	}
+	signals <- syscall.SIGTERM
+	if err := <-errChan; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []rununtil.Phase{rununtil.PhaseStarting, rununtil.PhaseRunning, rununtil.PhaseDraining, rununtil.PhaseStopped}
+	if len(metrics.phases) != len(expected) {
+		t.Fatalf("expected the phases %v, got: %v", expected, metrics.phases)
+	}
+	for idx, phase := range expected {
+		if metrics.phases[idx] != phase {
+			t.Fatalf("expected the phases %v, got: %v", expected, metrics.phases)
+		}
+	}
+	if metrics.shutdowns != 1 {
+		t.Fatalf("expected one shutdown to have been started, got: %d", metrics.shutdowns)
+	}
+	running := []int{1, 2, 1, 0}
+	if len(metrics.running) != len(running) {
+		t.Fatalf("expected the running runners to go %v, got: %v", running, metrics.running)
+	}
+	for idx, n := range running {
+		if metrics.running[idx] != n {
+			t.Fatalf("expected the running runners to go %v, got: %v", running, metrics.running)
+		}
+	}
+}
+
+func TestPhaseString(t *testing.T) {
+	for phase, expected := range map[rununtil.Phase]string{
+		rununtil.PhaseStarting: "starting",
+		rununtil.PhaseRunning:  "running",
+		rununtil.PhaseDraining: "draining",
+		rununtil.PhaseStopped:  "stopped",
+		rununtil.Phase(0):      "phase 0",
+	} {
+		if phase.String() != expected {
+			t.Fatalf("expected %q, got: %q", expected, phase.String())
+		}
+	}
+}
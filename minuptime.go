@@ -0,0 +1,56 @@
+package rununtil
+
+import "time"
+
+// WithMinUptime keeps the runners running until they have been up for at
+// least d, if a kill signal is received before then, so that a process which
+// is sent SIGTERM almost as soon as it has started, e.g. by a racy readiness
+// check during a rolling deploy, doesn't churn by shutting straight back down.
+// The uptime is measured from when the last runner started. A second kill
+// signal, cancelling the await or ForceNow ends the wait early, and shutdown
+// begun any other way is never delayed. A duration of zero, the default,
+// shuts down as soon as a kill signal is received.
+func WithMinUptime(d time.Duration) Option {
+	return func(o *options) {
+		o.minUptime = d
+	}
+}
+
+// waitForMinUptime waits out what is left of the minimum uptime, given when
+// the runners started, running the actions of any other signals which are
+// received in the meantime.
+func (s *session) waitForMinUptime(startedAt time.Time) {
+	remaining := s.opts.minUptime - s.opts.clock.Now().Sub(startedAt)
+	if s.opts.minUptime <= 0 || remaining <= 0 {
+		return
+	}
+	s.opts.logger.Infof("up for less than the minimum uptime of %v, waiting %v before shutting down", s.opts.minUptime, remaining)
+	expired := s.opts.clock.After(remaining)
+	for {
+		select {
+		case <-expired:
+			return
+		case <-s.hurry:
+			return
+		case <-s.finish.done:
+			return
+		case sig, ok := <-s.signals:
+			if !ok {
+				s.signals = nil
+				continue
+			}
+			s.opts.metrics.IncSignalReceived(sig.String())
+			if s.coalesced(sig) {
+				continue
+			}
+			if s.isKillSignal(sig) {
+				s.opts.logger.Infof("received signal %v before the minimum uptime, shutting down now", sig)
+				return
+			}
+			s.opts.logger.Infof("received signal %v, running its actions", sig)
+			for _, action := range s.opts.actions[sig] {
+				action()
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package rununtil_test
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func helperAwaitMinUptime(t *testing.T, clock *fakeClock, signals <-chan os.Signal, actions ...func(r *rununtil.Runner)) <-chan error {
+	t.Helper()
+	r := rununtil.New(rununtil.WithClock(clock), rununtil.WithSignalSource(signals), rununtil.WithMinUptime(time.Minute))
+	for _, action := range actions {
+		action(r)
+	}
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- r.Await()
+	}()
+	return errChan
+}
+
+func TestRununtilWithMinUptime(t *testing.T) {
+	clock := newFakeClock()
+	signals := make(chan os.Signal)
+	errChan := helperAwaitMinUptime(t, clock, signals)
+
+	signals <- syscall.SIGTERM
+	<-clock.waiting
+	select {
+	case err := <-errChan:
+		t.Fatalf("expected to wait out the minimum uptime, got: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	clock.advance(time.Minute)
+	if err := <-errChan; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRununtilWithMinUptime_SecondSignal(t *testing.T) {
+	clock := newFakeClock()
+	signals := make(chan os.Signal)
+	errChan := helperAwaitMinUptime(t, clock, signals)
+
+	signals <- syscall.SIGTERM
+	<-clock.waiting
+	signals <- syscall.SIGTERM
+	select {
+	case err := <-errChan:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the second signal to have ended the wait")
+	}
+}
+
+func TestRununtilWithMinUptime_AlreadyUp(t *testing.T) {
+	clock := newFakeClock()
+	signals := make(chan os.Signal)
+	reloaded := make(chan struct{}, 1)
+	errChan := helperAwaitMinUptime(t, clock, signals, func(r *rununtil.Runner) {
+		r.OnSignal(syscall.SIGHUP, func() { reloaded <- struct{}{} })
+	})
+
+	// the SIGHUP is only read once the runners have started
+	signals <- syscall.SIGHUP
+	<-reloaded
+	clock.advance(time.Minute)
+	signals <- syscall.SIGTERM
+	select {
+	case err := <-errChan:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected not to wait once the minimum uptime had passed")
+	}
+}
@@ -0,0 +1,244 @@
+package rununtil
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// ErrDependencyCycle is returned when a runner is added with dependencies that
+// would, directly or indirectly, depend on the runner itself.
+var ErrDependencyCycle = errors.New("dependency cycle")
+
+// component is a starter along with the name, dependencies and priority that
+// it was added with, if any, and the runner it was made from.
+type component struct {
+	name     string
+	deps     []string
+	priority ShutdownPriority
+	start    starter
+	// origin is the runner func that the starter was made from, which the
+	// component is labelled after if it wasn't given a name.
+	origin interface{}
+}
+
+// running is a component which has started, along with its shutdown.
+type running struct {
+	component
+	stop stopFunc
+}
+
+// describe returns how the component with the index is referred to in errors
+// and log lines.
+func (c component) describe(idx int) string {
+	label := c.label()
+	if label == "" {
+		return fmt.Sprintf("runner %d", idx)
+	}
+	return fmt.Sprintf("runner %d (%s)", idx, label)
+}
+
+// label returns the component's name or, if it wasn't given one, the name of
+// the func it was made from, e.g. main.NewHTTPRunner.func1. It is only worked
+// out when it is needed, since it is only used for describing the component.
+func (c component) label() string {
+	if c.name != "" {
+		return c.name
+	}
+	return funcName(c.origin)
+}
+
+// funcName returns the name of the func, without the directories of its
+// package's import path, or nothing if it isn't a func or its name can't be
+// found.
+func funcName(fn interface{}) string {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return ""
+	}
+	f := runtime.FuncForPC(v.Pointer())
+	if f == nil {
+		return ""
+	}
+	name := f.Name()
+	if slash := strings.LastIndex(name, "/"); slash >= 0 {
+		name = name[slash+1:]
+	}
+	// method values are wrapped in a func with the -fm suffix
+	return strings.TrimSuffix(name, "-fm")
+}
+
+// AddNamed is the same as Add, except that the RunnerFunc is given a name and
+// the names of the runners that it depends on. Rather than simply being shut
+// down in the reverse order to which the runners were started, a runner is
+// only shut down once everything that depends on it has been shut down, even
+// with diamond shaped dependencies:
+//
+//	r.AddNamed("db", nil, dbRunner)
+//	r.AddNamed("cache", []string{"db"}, cacheRunner)
+//	r.AddNamed("api", []string{"db", "cache"}, apiRunner)
+//
+// The dependencies don't have to have been added yet, but any that still
+// haven't been by the time of shutdown are ignored. The named runners which
+// are added before the first await are started in dependency order, after the
+// await's own RunnerFuncs, whereas those which are added during an await are
+// started straight away. It returns an error wrapping
+// ErrDependencyCycle, and doesn't add the RunnerFunc, if its dependencies
+// would lead back to it, and an error if the name has already been used.
+func (r *Runner) AddNamed(name string, deps []string, runner RunnerFunc) error {
+	if err := r.addDependencies(name, deps); err != nil {
+		return err
+	}
+	err := r.add(component{name: name, deps: deps, start: runner.asStarter(), origin: runner})
+	if err != nil {
+		r.mux.Lock()
+		delete(r.graph, name)
+		r.mux.Unlock()
+	}
+	return err
+}
+
+// addDependencies adds the named runner to the Runner's dependency graph, as
+// long as it doesn't introduce a cycle.
+func (r *Runner) addDependencies(name string, deps []string) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if _, ok := r.graph[name]; ok {
+		return fmt.Errorf("a runner named %q has already been added", name)
+	}
+	if r.graph == nil {
+		r.graph = make(map[string][]string)
+	}
+	for _, dep := range deps {
+		if path := findPath(r.graph, dep, name, nil); path != nil {
+			return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(append([]string{name}, path...), " -> "))
+		}
+	}
+	r.graph[name] = deps
+	return nil
+}
+
+// findPath returns the path of dependencies that leads from one runner to
+// another, or nil if there isn't one.
+func findPath(graph map[string][]string, from, to string, visited map[string]bool) []string {
+	if from == to {
+		return []string{to}
+	}
+	if visited == nil {
+		visited = make(map[string]bool)
+	}
+	if visited[from] {
+		return nil
+	}
+	visited[from] = true
+	for _, dep := range graph[from] {
+		if path := findPath(graph, dep, to, visited); path != nil {
+			return append([]string{from}, path...)
+		}
+	}
+	return nil
+}
+
+// startOrder returns the components in the order they should be started, which
+// is the order they were added in except that a component's dependencies are
+// always started before it.
+func startOrder(components []component) []component {
+	byName := make(map[string]int, len(components))
+	for idx, c := range components {
+		if c.name != "" {
+			byName[c.name] = idx
+		}
+	}
+	ordered := make([]component, 0, len(components))
+	visited := make([]bool, len(components))
+	var visit func(idx int)
+	visit = func(idx int) {
+		if visited[idx] {
+			return
+		}
+		visited[idx] = true
+		for _, dep := range components[idx].deps {
+			if depIdx, ok := byName[dep]; ok {
+				visit(depIdx)
+			}
+		}
+		ordered = append(ordered, components[idx])
+	}
+	for idx := range components {
+		visit(idx)
+	}
+	return ordered
+}
+
+// indexByName returns the index of each of the named components.
+func indexByName(components []running) map[string]int {
+	byName := make(map[string]int, len(components))
+	for idx, c := range components {
+		if c.name != "" {
+			byName[c.name] = idx
+		}
+	}
+	return byName
+}
+
+// dependentsOf returns, for each of the running components, the indexes of the
+// components which depend on it.
+func dependentsOf(components []running) [][]int {
+	byName := indexByName(components)
+	dependents := make([][]int, len(components))
+	for idx, c := range components {
+		for _, dep := range c.deps {
+			if depIdx, ok := byName[dep]; ok {
+				dependents[depIdx] = append(dependents[depIdx], idx)
+			}
+		}
+	}
+	return dependents
+}
+
+// dependencyOrder returns the indexes of the running components in the order
+// they should be shut down, which is the reverse of the order they were
+// started in, or the same order if forward is set, except that the Critical
+// components come before the BestEffort ones and a component is always shut
+// down after everything which depends on it.
+func dependencyOrder(components []running, forward bool) []int {
+	byName := indexByName(components)
+	remaining := make([]int, len(components))
+	for idx, dependents := range dependentsOf(components) {
+		remaining[idx] = len(dependents)
+	}
+
+	order := make([]int, 0, len(components))
+	done := make([]bool, len(components))
+	for len(order) < len(components) {
+		next := -1
+		for i := range components {
+			idx := len(components) - 1 - i
+			if forward {
+				idx = i
+			}
+			if !done[idx] && remaining[idx] == 0 && (next < 0 || components[idx].priority < components[next].priority) {
+				next = idx
+			}
+		}
+		if next < 0 {
+			// the dependencies are checked for cycles when they are added,
+			// so this can't happen, but carry on in reverse order anyway
+			for idx := len(components) - 1; next < 0; idx-- {
+				if !done[idx] {
+					next = idx
+				}
+			}
+		}
+		done[next] = true
+		order = append(order, next)
+		for _, dep := range components[next].deps {
+			if depIdx, ok := byName[dep]; ok {
+				remaining[depIdx]--
+			}
+		}
+	}
+	return order
+}
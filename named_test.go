@@ -0,0 +1,220 @@
+package rununtil_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+// orderRecorder records the order in which runners are started and shut down.
+type orderRecorder struct {
+	mux      sync.Mutex
+	started  []string
+	shutdown []string
+}
+
+func (rec *orderRecorder) runner(name string) rununtil.RunnerFunc {
+	return rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		rec.mux.Lock()
+		rec.started = append(rec.started, name)
+		rec.mux.Unlock()
+		return func() {
+			rec.mux.Lock()
+			rec.shutdown = append(rec.shutdown, name)
+			rec.mux.Unlock()
+		}
+	})
+}
+
+// helperIndexOf returns the position of name in names.
+func helperIndexOf(names []string, name string) int {
+	for idx, n := range names {
+		if n == name {
+			return idx
+		}
+	}
+	return -1
+}
+
+func helperAddDiamond(t *testing.T, r *rununtil.Runner, rec *orderRecorder) {
+	t.Helper()
+	// added before their dependencies, so that the order they were added in
+	// is the wrong order to start them in
+	for _, add := range []struct {
+		name string
+		deps []string
+	}{
+		{name: "api", deps: []string{"cache", "db"}},
+		{name: "worker", deps: []string{"db"}},
+		{name: "cache", deps: []string{"db"}},
+		{name: "db"},
+	} {
+		if err := r.AddNamed(add.name, add.deps, rec.runner(add.name)); err != nil {
+			t.Fatalf("unexpected error adding %s: %v", add.name, err)
+		}
+	}
+}
+
+func helperAssertBefore(t *testing.T, names []string, first, second string) {
+	t.Helper()
+	if helperIndexOf(names, first) > helperIndexOf(names, second) {
+		t.Fatalf("expected %s before %s, got: %v", first, second, names)
+	}
+}
+
+func TestRunner_AddNamed(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		opts []rununtil.Option
+	}{
+		{name: "Sequential shutdown"},
+		{name: "Concurrent shutdown", opts: []rununtil.Option{rununtil.WithConcurrentShutdown()}},
+		{name: "Forward shutdown", opts: []rununtil.Option{rununtil.WithShutdownOrder(rununtil.OrderForward)}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			rec := &orderRecorder{}
+			r := rununtil.New(test.opts...)
+			helperAddDiamond(t, r, rec)
+
+			errChan := make(chan error)
+			go func() {
+				errChan <- r.Await()
+			}()
+			if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(rec.started) != 4 || len(rec.shutdown) != 4 {
+				t.Fatalf("expected all of the runners to have started and shut down, got: %v and %v", rec.started, rec.shutdown)
+			}
+			for _, dep := range [][2]string{{"db", "cache"}, {"db", "api"}, {"cache", "api"}, {"db", "worker"}} {
+				helperAssertBefore(t, rec.started, dep[0], dep[1])
+				helperAssertBefore(t, rec.shutdown, dep[1], dep[0])
+			}
+		})
+	}
+}
+
+func TestRunner_AddNamed_Cycle(t *testing.T) {
+	rec := &orderRecorder{}
+	r := rununtil.New()
+	if err := r.AddNamed("a", []string{"b"}, rec.runner("a")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.AddNamed("b", []string{"c"}, rec.runner("b")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err := r.AddNamed("c", []string{"a"}, rec.runner("c"))
+	if !errors.Is(err, rununtil.ErrDependencyCycle) {
+		t.Fatalf("expected a dependency cycle error, got: %v", err)
+	}
+	if err.Error() != "dependency cycle: c -> a -> b -> c" {
+		t.Fatalf("expected the cycle to be described, got: %v", err)
+	}
+	if err := r.AddNamed("self", []string{"self"}, rec.runner("self")); !errors.Is(err, rununtil.ErrDependencyCycle) {
+		t.Fatalf("expected a dependency on itself to be a cycle, got: %v", err)
+	}
+
+	// the rejected runner can be added again without the cycle
+	if err := r.AddNamed("c", nil, rec.runner("c")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRunner_AddNamed_Duplicate(t *testing.T) {
+	rec := &orderRecorder{}
+	r := rununtil.New()
+	if err := r.AddNamed("db", nil, rec.runner("db")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.AddNamed("db", nil, rec.runner("db")); err == nil {
+		t.Fatal("expected adding a second runner with the same name to fail")
+	}
+}
+
+func TestRunner_AddNamed_WhileAwaiting(t *testing.T) {
+	rec := &orderRecorder{}
+	started := make(chan struct{})
+	r := rununtil.New()
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+			close(started)
+			return func() {}
+		}))
+	}()
+	<-started
+
+	// the client is started before the server it depends on, but it must
+	// still be shut down first
+	if err := r.AddNamed("client", []string{"server"}, rec.runner("client")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.AddNamed("server", nil, rec.runner("server")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	helperAssertBefore(t, rec.started, "client", "server")
+	helperAssertBefore(t, rec.shutdown, "client", "server")
+}
+
+func quietRunner() rununtil.ShutdownFunc {
+	return func() {}
+}
+
+type quietServer struct{}
+
+func (quietServer) Run() rununtil.ShutdownFunc {
+	return func() {}
+}
+
+func TestRununtilDerivedNames(t *testing.T) {
+	results := make(chan reportResult)
+	go func() {
+		report, err := rununtil.AwaitKillSignalsResult(nil, nil, quietRunner, quietServer{}.Run, func() rununtil.ShutdownFunc {
+			return func() {}
+		})
+		results <- reportResult{report: report, err: err}
+	}()
+	result := helperCancelUntilDone(t, results)
+	if result.err != nil {
+		t.Fatalf("unexpected error: %v", result.err)
+	}
+
+	expected := []string{
+		"rununtil_test.quietRunner",
+		"rununtil_test.quietServer.Run",
+		"rununtil_test.TestRununtilDerivedNames.func1.1",
+	}
+	if len(result.report.Runners) != len(expected) {
+		t.Fatalf("expected a result for each runner, got: %+v", result.report.Runners)
+	}
+	for idx, runner := range result.report.Runners {
+		if runner.Name != expected[idx] {
+			t.Fatalf("expected runner %d to be named after its func %s, got: %q", idx, expected[idx], runner.Name)
+		}
+	}
+}
+
+func TestRununtilDerivedNames_Explicit(t *testing.T) {
+	logger := &fakeLogger{}
+	r := rununtil.New(rununtil.WithLogger(logger))
+	if err := r.AddNamed("db", nil, func() rununtil.ShutdownFunc { return nil }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(quietRunner)
+	}()
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !logger.contains("ERROR: runner 1 (db) returned a nil shutdown function") {
+		t.Fatalf("expected the explicit name to be used, got: %v", logger.lines)
+	}
+}
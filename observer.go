@@ -0,0 +1,46 @@
+package rununtil
+
+import (
+	"context"
+	"os"
+)
+
+// ShutdownObserver observes the graceful shutdown, e.g. to trace it. Each of
+// its methods returns the context that the rest of the shutdown is observed
+// within, so that the observations can be nested, along with a function which
+// is called with the outcome once that stage of the shutdown has finished.
+type ShutdownObserver interface {
+	// StartShutdown is called as soon as shutdown begins with the kill
+	// signal that triggered it, or nil if it was triggered some other way,
+	// and the number of runners that are being shut down.
+	StartShutdown(ctx context.Context, sig os.Signal, runners int) (context.Context, func(err error))
+	// StartRunnerShutdown is called just before the shutdown function of the
+	// runner with the index, in the order the runners were provided, is
+	// executed.
+	StartRunnerShutdown(ctx context.Context, idx int) (context.Context, func(err error))
+}
+
+// WithShutdownObserver adds a ShutdownObserver which observes the graceful
+// shutdown. The contexts that it returns are passed to the CtxShutdownFuncs.
+func WithShutdownObserver(observer ShutdownObserver) Option {
+	return func(o *options) {
+		o.observers = append(o.observers, observer)
+	}
+}
+
+// observe starts observing a stage of the shutdown with each of the
+// observers, returning the context to use for that stage and a function which
+// finishes observing it.
+func observe(ctx context.Context, observers []ShutdownObserver, start func(ShutdownObserver, context.Context) (context.Context, func(error))) (context.Context, func(error)) {
+	finishes := make([]func(error), 0, len(observers))
+	for _, observer := range observers {
+		var finish func(error)
+		ctx, finish = start(observer, ctx)
+		finishes = append(finishes, finish)
+	}
+	return ctx, func(err error) {
+		for idx := len(finishes) - 1; idx >= 0; idx-- {
+			finishes[idx](err)
+		}
+	}
+}
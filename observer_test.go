@@ -0,0 +1,79 @@
+package rununtil_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+type observerKey struct{}
+
+// fakeObserver records the stages of the shutdown that it observes.
+type fakeObserver struct {
+	mux    sync.Mutex
+	events []string
+}
+
+func (o *fakeObserver) record(format string, args ...interface{}) {
+	o.mux.Lock()
+	defer o.mux.Unlock()
+	o.events = append(o.events, fmt.Sprintf(format, args...))
+}
+
+func (o *fakeObserver) StartShutdown(ctx context.Context, sig os.Signal, runners int) (context.Context, func(err error)) {
+	o.record("start shutdown of %d runners after %v", runners, sig)
+	return context.WithValue(ctx, observerKey{}, "shutdown"), func(err error) {
+		o.record("finish shutdown, failed: %t", err != nil)
+	}
+}
+
+func (o *fakeObserver) StartRunnerShutdown(ctx context.Context, idx int) (context.Context, func(err error)) {
+	o.record("start runner %d within %v", idx, ctx.Value(observerKey{}))
+	return ctx, func(err error) {
+		o.record("finish runner %d, failed: %t", idx, err != nil)
+	}
+}
+
+func TestRununtilWithShutdownObserver(t *testing.T) {
+	observer := &fakeObserver{}
+	var hasBeenShutdown atomic.Bool
+	panickingRunner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		return func() { panic("boom") }
+	})
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- rununtil.AwaitKillSignalsWithOptions(
+			[]os.Signal{syscall.SIGINT},
+			[]rununtil.Option{rununtil.WithShutdownObserver(observer)},
+			helperMakeFakeRunner(&hasBeenShutdown),
+			panickingRunner,
+		)
+	}()
+	if err := helperCancelUntilDone(t, errChan); err == nil {
+		t.Fatal("expected the panicking shutdown function to have failed")
+	}
+
+	expected := []string{
+		"start shutdown of 2 runners after <nil>",
+		"start runner 1 within shutdown",
+		"finish runner 1, failed: true",
+		"start runner 0 within shutdown",
+		"finish runner 0, failed: false",
+		"finish shutdown, failed: true",
+	}
+	if len(observer.events) != len(expected) {
+		t.Fatalf("expected %v to have been observed, got: %v", expected, observer.events)
+	}
+	for idx := range expected {
+		if observer.events[idx] != expected[idx] {
+			t.Fatalf("expected %v to have been observed, got: %v", expected, observer.events)
+		}
+	}
+}
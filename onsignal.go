@@ -0,0 +1,54 @@
+package rununtil
+
+import "os"
+
+// OnSignal registers the action to be run whenever the signal is received by
+// an await of the package level functions, such as AwaitKillSignal, see
+// Runner.OnSignal.
+func OnSignal(sig os.Signal, action func()) {
+	defaultRunner.OnSignal(sig, action)
+}
+
+// OnSignal registers the action to be run whenever the signal is received
+// while the Runner is awaiting, instead of shutting down, e.g. to dump the go
+// routine stacks on SIGUSR1 or toggle debug logging on SIGUSR2:
+//
+//	r.OnSignal(syscall.SIGUSR1, dumpStacks)
+//	r.OnSignal(syscall.SIGUSR2, toggleDebug)
+//
+// The actions are run in the same loop which waits for the kill signals, so
+// there is no need for a competing signal.Notify which could steal the
+// signals from the await. A signal can have several actions, which are run one
+// after the other in the order they were registered, and no other signal is
+// handled until they have all returned. The kill signals take precedence, so
+// a signal which is one of them still shuts down and its actions are never
+// run. The actions apply to the awaits which begin after they are registered.
+func (r *Runner) OnSignal(sig os.Signal, action func()) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.actions == nil {
+		r.actions = make(map[os.Signal][]func())
+	}
+	r.actions[sig] = append(r.actions[sig], action)
+}
+
+// withActions returns the actions along with those which have been registered
+// with OnSignal, leaving out the kill signals.
+func (r *Runner) withActions(killSignals []os.Signal, actions map[os.Signal][]func()) map[os.Signal][]func() {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if len(r.actions) == 0 {
+		return actions
+	}
+	merged := make(map[os.Signal][]func(), len(actions)+len(r.actions))
+	for sig, sigActions := range actions {
+		merged[sig] = sigActions
+	}
+	for sig, sigActions := range r.actions {
+		if containsSignal(killSignals, sig) {
+			continue
+		}
+		merged[sig] = append(append([]func(){}, merged[sig]...), sigActions...)
+	}
+	return merged
+}
@@ -0,0 +1,88 @@
+package rununtil_test
+
+import (
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRunner_OnSignal(t *testing.T) {
+	actions := make(chan string, 4)
+	signals := make(chan os.Signal)
+	r := rununtil.New(rununtil.WithSignalSource(signals))
+	r.OnSignal(syscall.SIGHUP, func() { actions <- "first" })
+	r.OnSignal(syscall.SIGHUP, func() { actions <- "second" })
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await()
+	}()
+	for i := 0; i < 2; i++ {
+		signals <- syscall.SIGHUP
+		for _, expected := range []string{"first", "second"} {
+			if action := <-actions; action != expected {
+				t.Fatalf("expected the %s action, got: %s", expected, action)
+			}
+		}
+	}
+	select {
+	case err := <-errChan:
+		t.Fatalf("expected the actions not to have stopped the await, got: %v", err)
+	default:
+	}
+
+	signals <- syscall.SIGTERM
+	if err := <-errChan; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRunner_OnSignal_KillSignal(t *testing.T) {
+	var hasBeenShutdown, hasRunAction atomic.Bool
+	signals := make(chan os.Signal)
+	r := rununtil.New(rununtil.WithSignalSource(signals))
+	r.OnSignal(syscall.SIGTERM, func() { hasRunAction.Store(true) })
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(helperMakeFakeRunner(&hasBeenShutdown))
+	}()
+	signals <- syscall.SIGTERM
+	if err := <-errChan; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the kill signal to have shut down the runner")
+	}
+	if hasRunAction.Load() {
+		t.Fatal("expected the kill signal's action not to have been run")
+	}
+}
+
+func TestRununtilOnSignal(t *testing.T) {
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("unexpected error occurred: %v", err)
+	}
+	var sentSignal atomic.Bool
+	actions := make(chan struct{}, 1)
+	rununtil.OnSignal(syscall.SIGHUP, func() { actions <- struct{}{} })
+	defer rununtil.ResetOnSignal()
+
+	done := make(chan struct{})
+	go func() {
+		rununtil.AwaitKillSignal()
+		close(done)
+	}()
+	go helperSendSignal(t, p, &sentSignal, syscall.SIGHUP, time.Millisecond)
+	select {
+	case <-actions:
+	case <-time.After(time.Second):
+		t.Fatal("expected the action to have been run")
+	}
+	helperCancelUntilDone(t, done)
+}
@@ -0,0 +1,256 @@
+package rununtil
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+)
+
+// ShutdownOrder controls the order in which the shutdown functions are
+// executed, and is set with WithShutdownOrder.
+type ShutdownOrder int
+
+const (
+	// OrderReverse executes the shutdown functions sequentially in the
+	// reverse order to which their runners were registered. This is the
+	// default.
+	OrderReverse ShutdownOrder = iota
+	// OrderForward executes the shutdown functions sequentially in the order
+	// in which their runners were registered.
+	OrderForward
+	// OrderParallel executes all of the shutdown functions at the same time.
+	OrderParallel
+)
+
+// options configures how the runners are awaited and shut down.
+type options struct {
+	// signals are the kill signals that a Runner awaits.
+	signals []os.Signal
+	// shutdownTimeout is how long each shutdown function is given to
+	// complete, where zero means wait forever.
+	shutdownTimeout time.Duration
+	// shutdownDeadline is the total time that all of the shutdown functions
+	// are given to complete, where zero means wait forever.
+	shutdownDeadline time.Duration
+	// order is the order in which the shutdown functions are executed.
+	order ShutdownOrder
+	// panicHandler is called with the recovered value when a runner panics.
+	panicHandler func(recovered interface{})
+	// actions are run, instead of shutting down, when their signal is
+	// received.
+	actions map[os.Signal][]func()
+	// clock tells the time for the await.
+	clock Clock
+	// signalSource, if set, is where the signals are received from instead of
+	// the operating system.
+	signalSource <-chan os.Signal
+	// metrics records metrics about the await.
+	metrics Metrics
+	// observers observe the graceful shutdown.
+	observers []ShutdownObserver
+	// logger logs the lifecycle events of the await.
+	logger Logger
+	// onStarted are called once all of the runners have started, and the
+	// readiness probe, if there is one, has passed.
+	onStarted []func(log Logger)
+	// preShutdown are called as soon as the await has been told to stop,
+	// while the runners are still running.
+	preShutdown []func()
+	// lameDuckDelay is how long the runners are left running, after the
+	// preShutdown hooks have been called, before shutdown begins.
+	lameDuckDelay time.Duration
+	// onStopping are called as soon as shutdown begins, before the
+	// pre-shutdown hooks, the lame duck delay and any of the shutdown
+	// functions.
+	onStopping []func(log Logger)
+	// maxLifetime is how long the runners are run for before shutdown begins
+	// as if a kill signal had been received, or zero to run them until one
+	// is.
+	maxLifetime time.Duration
+	// forceQuit exits the process if a kill signal is received during
+	// shutdown, rather than just logging it.
+	forceQuit bool
+	// ctx stops the await, in the same way as a kill signal, once it is
+	// done.
+	ctx context.Context
+	// done stops the await, in the same way as a kill signal, once it is
+	// closed.
+	done <-chan struct{}
+	// reporter collects the results of the shutdown, if one has been asked
+	// for.
+	reporter *shutdownReporter
+	// reportJSON, if it is set, has the ShutdownReport written to it as JSON
+	// once shutdown has completed.
+	reportJSON io.Writer
+	// exit exits the process, instead of os.Exit, if it is set.
+	exit func(code int)
+	// ignored are the signals which are ignored while the await is running.
+	ignored []os.Signal
+	// staggerInterval is how long to wait between each of the sequential
+	// shutdown functions.
+	staggerInterval time.Duration
+	// staggerJitter is the most that is randomly added to each
+	// staggerInterval.
+	staggerJitter time.Duration
+	// requireRunners makes an await fail if it has no runners to run.
+	requireRunners bool
+	// catchAllFatal also awaits the fatal signals which aren't kill signals.
+	catchAllFatal bool
+	// startupTimeout is how long each runner is given to start, where zero
+	// means wait forever.
+	startupTimeout time.Duration
+	// profiles are used instead of the lame duck delay, shutdown timeout and
+	// shutdown order when shutdown is begun by their kill signal.
+	profiles map[os.Signal]ShutdownProfile
+	// signalBuffer is the size of the buffer of the channel which the
+	// signals are delivered on.
+	signalBuffer int
+	// minUptime is how long the runners are kept running, when a kill
+	// signal is received, since they started.
+	minUptime time.Duration
+	// shutdownWarnAfter is how long the shutdown can run for before the
+	// runners which are yet to finish are logged.
+	shutdownWarnAfter time.Duration
+	// pending keeps track of the shutdowns which are yet to finish, if
+	// WithShutdownWarnAfter has been given.
+	pending *pendingShutdowns
+	// statuses keeps track of the state of each of the runners, for
+	// Runner.Status.
+	statuses *statusTracker
+	// hardKillAfter is how long the shutdown is given before the process is
+	// exited, where zero means never.
+	hardKillAfter time.Duration
+	// immediate are the signals which are left to their default behaviour.
+	immediate []os.Signal
+	// leaderRelease is called as soon as the await has been told to stop,
+	// before the pre-shutdown hooks and the lame duck delay.
+	leaderRelease func(ctx context.Context) error
+	// reExecSignal is the kill signal which re-executes the binary once the
+	// shutdown has completed.
+	reExecSignal os.Signal
+	// finalFlush are called once the await has finished everything else.
+	finalFlush []func()
+	// progress is called each time a sequential shutdown function has
+	// completed.
+	progress func(done, total int, current string)
+	// readinessProbe is polled every readinessInterval, once the runners
+	// have started, until it passes.
+	readinessProbe    func(ctx context.Context) error
+	readinessInterval time.Duration
+	// shutdownTiming is called as each of the shutdown functions completes.
+	shutdownTiming func(name string, d time.Duration, err error)
+	// coalesceWindow is how long after the kill signal which began the
+	// shutdown repeats of it are ignored.
+	coalesceWindow time.Duration
+	// hurry is closed by ForceNow, to skip the rest of the shutdown's
+	// delays.
+	hurry <-chan struct{}
+}
+
+// Option configures how the runners are shut down.
+type Option func(*options)
+
+// newOptions returns the default options with opts applied to them.
+func newOptions(opts []Option) options {
+	o := options{
+		signals: defaultSignals(),
+		logger:  getDefaultLogger(),
+		metrics: nopMetrics{},
+		clock:   realClock{},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithSignals sets the kill signals that a Runner awaits, instead of the
+// default SIGINT and SIGTERM.
+func WithSignals(signals ...os.Signal) Option {
+	return func(o *options) {
+		o.signals = signals
+	}
+}
+
+// WithSequentialShutdown executes the shutdown functions strictly one after
+// the other in the reverse order to which their runners were registered, each
+// one fully completing before the next one begins. For example, if a database
+// runner is registered followed by an HTTP server runner, then the HTTP server
+// stops accepting requests before the database is shut down. This is the
+// default, and is the same as WithShutdownOrder(OrderReverse).
+func WithSequentialShutdown() Option {
+	return func(o *options) {
+		o.order = OrderReverse
+	}
+}
+
+// WithShutdownOrder sets the order in which the shutdown functions are
+// executed: OrderReverse, the default, OrderForward or OrderParallel. Whatever
+// the order, a runner added with Runner.AddNamed is always shut down after
+// everything which depends on it.
+func WithShutdownOrder(order ShutdownOrder) Option {
+	return func(o *options) {
+		o.order = order
+	}
+}
+
+// WithPanicHandler sets a function which is called with the recovered value
+// when a runner panics, before the runners that have already started are shut
+// down. The handler can log the panic or, if it is fatal, re-panic.
+func WithPanicHandler(handler func(recovered interface{})) Option {
+	return func(o *options) {
+		o.panicHandler = handler
+	}
+}
+
+// WithConcurrentShutdown executes every shutdown function in its own go
+// routine, all at the same time, and waits for all of them to complete. The
+// total shutdown time is then bounded by the slowest shutdown function, rather
+// than the sum of all of them. Combine it with WithShutdownTimeout so that a
+// single slow shutdown function can't hold up the rest. It is the same as
+// WithShutdownOrder(OrderParallel).
+func WithConcurrentShutdown() Option {
+	return func(o *options) {
+		o.order = OrderParallel
+	}
+}
+
+// WithShutdownTimeout gives each shutdown function the timeout to complete. If
+// it does not, the await stops waiting for it, carries on with the shutdown and
+// eventually returns an error wrapping ErrShutdownTimeout. A timeout of zero,
+// the default, means wait forever.
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.shutdownTimeout = timeout
+	}
+}
+
+// WithShutdownDeadline gives all of the shutdown functions, together, the total
+// time to complete. They share a context which is done once the deadline has
+// passed, and the await returns at that point, with an error wrapping
+// ErrShutdownDeadline, even if some of them are still running. A total of
+// zero, the default, means wait forever.
+func WithShutdownDeadline(total time.Duration) Option {
+	return func(o *options) {
+		o.shutdownDeadline = total
+	}
+}
+
+// WithLogger sets the Logger which is used to log the lifecycle events of the
+// await, such as which signal was received and how long the shutdown took. By
+// default the Logger set with SetLogger is used, which doesn't log anything
+// unless it has been set.
+func WithLogger(logger Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// AwaitKillSignalsWithOptions runs the provided RunnerFuncs until the specified
+// signals have been received, at which point it executes the graceful shutdown
+// functions as configured by the options. It returns any errors that occurred
+// during shutdown.
+func AwaitKillSignalsWithOptions(signals []os.Signal, opts []Option, runnerFuncs ...RunnerFunc) error {
+	return awaitKillSignals(signals, starters(runnerFuncs), newOptions(opts))
+}
@@ -0,0 +1,181 @@
+package rununtil_test
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+// shutdownRecorder records the order in which shutdown functions are executed
+// and how many of them were running at the same time.
+type shutdownRecorder struct {
+	mux        sync.Mutex
+	order      []int
+	running    int
+	maxRunning int
+}
+
+func (rec *shutdownRecorder) runner(idx int, delay time.Duration) rununtil.RunnerFunc {
+	return rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		return rununtil.ShutdownFunc(func() {
+			rec.mux.Lock()
+			rec.running++
+			if rec.running > rec.maxRunning {
+				rec.maxRunning = rec.running
+			}
+			rec.mux.Unlock()
+
+			time.Sleep(delay)
+
+			rec.mux.Lock()
+			rec.running--
+			rec.order = append(rec.order, idx)
+			rec.mux.Unlock()
+		})
+	})
+}
+
+func TestRununtilWithSequentialShutdown(t *testing.T) {
+	var rec shutdownRecorder
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- rununtil.AwaitKillSignalsWithOptions(
+			[]os.Signal{syscall.SIGINT},
+			[]rununtil.Option{rununtil.WithSequentialShutdown()},
+			rec.runner(1, 5*time.Millisecond),
+			rec.runner(2, 5*time.Millisecond),
+			rec.runner(3, 5*time.Millisecond),
+		)
+	}()
+	if err := helperCancelUntilDone(t, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []int{3, 2, 1}
+	if len(rec.order) != len(expected) {
+		t.Fatalf("expected shutdown order %v, got: %v", expected, rec.order)
+	}
+	for idx := range expected {
+		if rec.order[idx] != expected[idx] {
+			t.Fatalf("expected shutdown order %v, got: %v", expected, rec.order)
+		}
+	}
+	if rec.maxRunning != 1 {
+		t.Fatalf("expected the shutdown functions to run one at a time, got %d at once", rec.maxRunning)
+	}
+}
+
+func TestRununtilWithConcurrentShutdown(t *testing.T) {
+	var rec shutdownRecorder
+	delay := 100 * time.Millisecond
+
+	errChan := make(chan error)
+	var start time.Time
+	go func() {
+		errChan <- rununtil.AwaitKillSignalsWithOptions(
+			[]os.Signal{syscall.SIGINT},
+			[]rununtil.Option{rununtil.WithConcurrentShutdown()},
+			rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+				return func() { start = time.Now() }
+			}),
+			rec.runner(1, delay),
+			rec.runner(2, delay),
+			rec.runner(3, delay),
+		)
+	}()
+	if err := helperCancelUntilDone(t, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed >= 2*delay {
+		t.Fatalf("expected the shutdown to take about as long as the slowest shutdown function, took: %v", elapsed)
+	}
+	if len(rec.order) != 3 {
+		t.Fatalf("expected all of the shutdown functions to have been called, got: %v", rec.order)
+	}
+	if rec.maxRunning != 3 {
+		t.Fatalf("expected the shutdown functions to run at the same time, got %d at once", rec.maxRunning)
+	}
+}
+
+func TestRununtilWithConcurrentShutdown_WithShutdownTimeout(t *testing.T) {
+	var rec shutdownRecorder
+	hang := make(chan struct{})
+	defer close(hang)
+	hangingRunner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		return func() { <-hang }
+	})
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- rununtil.AwaitKillSignalsWithOptions(
+			[]os.Signal{syscall.SIGINT},
+			[]rununtil.Option{
+				rununtil.WithConcurrentShutdown(),
+				rununtil.WithShutdownTimeout(20 * time.Millisecond),
+			},
+			rec.runner(1, time.Millisecond),
+			hangingRunner,
+			rec.runner(3, time.Millisecond),
+		)
+	}()
+	if err := helperCancelUntilDone(t, errChan); !errors.Is(err, rununtil.ErrShutdownTimeout) {
+		t.Fatalf("expected a shutdown timeout error, got: %v", err)
+	}
+	if len(rec.order) != 2 {
+		t.Fatalf("expected the other shutdown functions to have completed, got: %v", rec.order)
+	}
+}
+
+func TestRununtilWithShutdownOrder(t *testing.T) {
+	delay := 20 * time.Millisecond
+	tests := []struct {
+		name       string
+		order      rununtil.ShutdownOrder
+		expected   []int
+		maxRunning int
+	}{
+		{name: "reverse", order: rununtil.OrderReverse, expected: []int{3, 2, 1}, maxRunning: 1},
+		{name: "forward", order: rununtil.OrderForward, expected: []int{1, 2, 3}, maxRunning: 1},
+		{name: "parallel", order: rununtil.OrderParallel, maxRunning: 3},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			var rec shutdownRecorder
+
+			errChan := make(chan error)
+			go func() {
+				errChan <- rununtil.AwaitKillSignalsWithOptions(
+					[]os.Signal{syscall.SIGINT},
+					[]rununtil.Option{rununtil.WithShutdownOrder(test.order)},
+					rec.runner(1, delay),
+					rec.runner(2, delay),
+					rec.runner(3, delay),
+				)
+			}()
+			if err := helperCancelUntilDone(t, errChan); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(rec.order) != 3 {
+				t.Fatalf("expected all of the shutdown functions to have been called, got: %v", rec.order)
+			}
+			for idx := range test.expected {
+				if rec.order[idx] != test.expected[idx] {
+					t.Fatalf("expected shutdown order %v, got: %v", test.expected, rec.order)
+				}
+			}
+			if rec.maxRunning != test.maxRunning {
+				t.Fatalf("expected %d shutdown functions to run at once, got: %d", test.maxRunning, rec.maxRunning)
+			}
+		})
+	}
+}
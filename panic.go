@@ -0,0 +1,56 @@
+package rununtil
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is returned when a runner panics. When this happens the panic is
+// treated in the same way as a kill signal: the runners that have already
+// started are shut down and the await returns the PanicError. It is also
+// returned, wrapped, when a shutdown function panics, in which case the rest of
+// the shutdown functions are still executed. The await functions that cannot
+// return an error re-panic with the PanicError once shutdown has completed.
+type PanicError struct {
+	// Value is the value that was recovered from the panic.
+	Value interface{}
+	// Stack is the stack trace of the go routine that panicked.
+	Stack []byte
+}
+
+func newPanicError(recovered interface{}) *PanicError {
+	return &PanicError{Value: recovered, Stack: debug.Stack()}
+}
+
+// asPanicError keeps a recovered PanicError as it is, e.g. one re-panicked by
+// CombineShutdown, so that the stack trace of the original panic is kept.
+func asPanicError(recovered interface{}) *PanicError {
+	if panicErr, ok := recovered.(*PanicError); ok {
+		return panicErr
+	}
+	return newPanicError(recovered)
+}
+
+// Error returns the recovered value on one line, so that it can be logged
+// along with other errors. The stack trace is kept in Stack instead.
+func (p *PanicError) Error() string {
+	return fmt.Sprintf("runner panicked: %v", p.Value)
+}
+
+// Unwrap returns the recovered value if it was an error.
+func (p *PanicError) Unwrap() error {
+	if err, ok := p.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// repanic re-panics with the PanicError if err contains one, for the await
+// functions which have no way of returning it.
+func repanic(err error) {
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		panic(panicErr)
+	}
+}
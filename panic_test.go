@@ -0,0 +1,140 @@
+package rununtil_test
+
+import (
+	"errors"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func helperMakePanickingRunner(value interface{}) rununtil.RunnerFunc {
+	return rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		panic(value)
+	})
+}
+
+func TestRunner_RunnerPanics(t *testing.T) {
+	var hasBeenShutdown1, hasBeenShutdown3 atomic.Bool
+	var handled interface{}
+	r := rununtil.New(rununtil.WithPanicHandler(func(recovered interface{}) {
+		handled = recovered
+	}))
+
+	err := r.Await(
+		helperMakeFakeRunner(&hasBeenShutdown1),
+		helperMakePanickingRunner("address already in use"),
+		helperMakeFakeRunner(&hasBeenShutdown3),
+	)
+
+	var panicErr *rununtil.PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("expected a panic error, got: %v", err)
+	}
+	if panicErr.Value != "address already in use" {
+		t.Fatalf("expected the panic value to be returned, got: %v", panicErr.Value)
+	}
+	if msg := panicErr.Error(); msg != "runner panicked: address already in use" {
+		t.Fatalf("expected the error to be the panic value on one line, got: %q", msg)
+	}
+	if len(panicErr.Stack) == 0 {
+		t.Fatal("expected the stack trace to have been kept")
+	}
+	if handled != "address already in use" {
+		t.Fatalf("expected the panic handler to have been called, got: %v", handled)
+	}
+	if !hasBeenShutdown1.Load() {
+		t.Fatal("expected the runner started before the panic to have been shutdown")
+	}
+	if hasBeenShutdown3.Load() {
+		t.Fatal("expected the runner after the panic to never have been started")
+	}
+}
+
+func TestRunner_RunnerPanicsWithError(t *testing.T) {
+	panicked := errors.New("panicked")
+	err := rununtil.New().Await(helperMakePanickingRunner(panicked))
+	if !errors.Is(err, panicked) {
+		t.Fatalf("expected the error to wrap the panic value, got: %v", err)
+	}
+}
+
+func TestRununtilAwaitKillSignals_RePanics(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	defer func() {
+		recovered := recover()
+		panicErr, ok := recovered.(*rununtil.PanicError)
+		if !ok {
+			t.Fatalf("expected to re-panic with a panic error, got: %v", recovered)
+		}
+		if panicErr.Value != "boom" {
+			t.Fatalf("expected the panic value to be boom, got: %v", panicErr.Value)
+		}
+		if !hasBeenShutdown.Load() {
+			t.Fatal("expected the runner started before the panic to have been shutdown")
+		}
+	}()
+
+	rununtil.AwaitKillSignals(
+		[]os.Signal{syscall.SIGINT},
+		helperMakeFakeRunner(&hasBeenShutdown),
+		helperMakePanickingRunner("boom"),
+	)
+}
+
+func TestRunner_ShutdownPanics(t *testing.T) {
+	var hasBeenShutdown1, hasBeenShutdown3 atomic.Bool
+	panickingRunner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		return func() {
+			panic("close of closed channel")
+		}
+	})
+	r := rununtil.New()
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(
+			helperMakeFakeRunner(&hasBeenShutdown1),
+			panickingRunner,
+			helperMakeFakeRunner(&hasBeenShutdown3),
+		)
+	}()
+	err := helperKeepCancelling(t, r.Cancel, errChan)
+
+	var panicErr *rununtil.PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("expected a panic error, got: %v", err)
+	}
+	if panicErr.Value != "close of closed channel" {
+		t.Fatalf("expected the panic value to be returned, got: %v", panicErr.Value)
+	}
+	if !hasBeenShutdown1.Load() || !hasBeenShutdown3.Load() {
+		t.Fatal("expected the other shutdown functions to have been called")
+	}
+}
+
+func TestRununtilAwaitKillSignals_ShutdownPanics(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	panickingRunner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		return func() {
+			panic("close of closed channel")
+		}
+	})
+
+	recovered := make(chan interface{})
+	go func() {
+		defer func() {
+			recovered <- recover()
+		}()
+		rununtil.AwaitKillSignals([]os.Signal{syscall.SIGINT}, helperMakeFakeRunner(&hasBeenShutdown), panickingRunner)
+	}()
+	value := helperCancelUntilDone(t, recovered)
+	if _, ok := value.(*rununtil.PanicError); !ok {
+		t.Fatalf("expected to re-panic with a PanicError, got: %v", value)
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the other shutdown function to have been called")
+	}
+}
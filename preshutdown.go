@@ -0,0 +1,61 @@
+package rununtil
+
+import "time"
+
+// WithPreShutdown adds a hook which is called as soon as a kill signal has
+// been received, or the await has been cancelled, before any of the runners
+// are told to stop. For example, it can start failing a readiness probe so
+// that a load balancer stops sending new requests. The hooks are called in the
+// order they were added.
+func WithPreShutdown(hook func()) Option {
+	return func(o *options) {
+		o.preShutdown = append(o.preShutdown, hook)
+	}
+}
+
+// WithLameDuckDelay leaves the runners running for the delay after the
+// WithPreShutdown hooks have been called, and before any of the shutdown
+// functions are executed, so that in flight and already routed requests can
+// still be served. This is the "lame duck" period of, for example, a
+// Kubernetes pod which has been removed from its service's endpoints.
+func WithLameDuckDelay(delay time.Duration) Option {
+	return func(o *options) {
+		o.lameDuckDelay = delay
+	}
+}
+
+// preShutdown calls the pre-shutdown hooks and then waits out the lame duck
+// delay, if there is one, unless the shutdown is hurried by ForceNow or by
+// another kill signal.
+func (s *session) preShutdown() {
+	for _, hook := range s.opts.preShutdown {
+		hook()
+	}
+	if s.opts.lameDuckDelay <= 0 {
+		return
+	}
+	s.opts.logger.Infof("lame duck for %v before shutting down", s.opts.lameDuckDelay)
+	expired := s.opts.clock.After(s.opts.lameDuckDelay)
+	signals := s.signals
+	if s.opts.forceQuit {
+		// the signals force quit instead
+		signals = nil
+	}
+	for {
+		select {
+		case <-expired:
+			return
+		case <-s.hurry:
+			return
+		case sig, ok := <-signals:
+			if !ok {
+				signals = nil
+				continue
+			}
+			if s.isKillSignal(sig) && !s.coalesced(sig) {
+				s.opts.logger.Infof("received signal %v during the lame duck delay", sig)
+				s.forceNow()
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package rununtil_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRununtilWithPreShutdown(t *testing.T) {
+	events := make(chan string, 3)
+	delay := 50 * time.Millisecond
+	r := rununtil.New(
+		rununtil.WithPreShutdown(func() { events <- "first pre-shutdown" }),
+		rununtil.WithPreShutdown(func() { events <- "second pre-shutdown" }),
+		rununtil.WithLameDuckDelay(delay),
+	)
+	runner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		return func() { events <- "shutdown" }
+	})
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(runner)
+	}()
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{"first pre-shutdown", "second pre-shutdown", "shutdown"} {
+		if event := <-events; event != expected {
+			t.Fatalf("expected %q, got: %q", expected, event)
+		}
+	}
+}
+
+func TestRununtilWithLameDuckDelay(t *testing.T) {
+	delay := 50 * time.Millisecond
+	var preShutdownAt, shutdownAt time.Time
+	r := rununtil.New(
+		rununtil.WithPreShutdown(func() { preShutdownAt = time.Now() }),
+		rununtil.WithLameDuckDelay(delay),
+	)
+	runner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		return func() { shutdownAt = time.Now() }
+	})
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(runner)
+	}()
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if elapsed := shutdownAt.Sub(preShutdownAt); elapsed < delay {
+		t.Fatalf("expected the runner to keep running for at least %v, got: %v", delay, elapsed)
+	}
+}
@@ -0,0 +1,29 @@
+package rununtil
+
+// ShutdownPriority controls whether a runner's shutdown function is executed
+// before or after the others, and is given to Runner.AddTagged.
+type ShutdownPriority int
+
+const (
+	// Critical shutdown functions must complete, e.g. to flush a write-ahead
+	// log, and are executed before any of the BestEffort ones. Runners which
+	// haven't been tagged are Critical.
+	Critical ShutdownPriority = iota
+	// BestEffort shutdown functions, e.g. closing a metrics exporter, are
+	// executed once all of the Critical ones have completed, and are given
+	// whatever remains of the WithShutdownDeadline deadline.
+	BestEffort
+)
+
+// AddTagged is the same as Add, except that the RunnerFunc is tagged with the
+// priority of its shutdown, so that a slow BestEffort shutdown can't use up the
+// time that a Critical one needed:
+//
+//	r.AddTagged(rununtil.Critical, walRunner)
+//	r.AddTagged(rununtil.BestEffort, metricsRunner)
+//
+// Within each priority the shutdown functions are executed in the configured
+// order, e.g. concurrently with OrderParallel.
+func (r *Runner) AddTagged(priority ShutdownPriority, runner RunnerFunc) error {
+	return r.add(component{priority: priority, start: runner.asStarter(), origin: runner})
+}
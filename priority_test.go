@@ -0,0 +1,90 @@
+package rununtil_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRunner_AddTagged(t *testing.T) {
+	shutdowns := make(chan string, 4)
+	helperMakeRunner := func(name string) rununtil.RunnerFunc {
+		return rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+			return func() { shutdowns <- name }
+		})
+	}
+	r := rununtil.New()
+	for _, tagged := range []struct {
+		priority rununtil.ShutdownPriority
+		name     string
+	}{
+		{rununtil.BestEffort, "first best-effort"},
+		{rununtil.Critical, "first critical"},
+		{rununtil.BestEffort, "second best-effort"},
+		{rununtil.Critical, "second critical"},
+	} {
+		if err := r.AddTagged(tagged.priority, helperMakeRunner(tagged.name)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await()
+	}()
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{"second critical", "first critical", "second best-effort", "first best-effort"} {
+		if name := <-shutdowns; name != expected {
+			t.Fatalf("expected the %s runner to be shut down, got: %s", expected, name)
+		}
+	}
+}
+
+func TestRunner_AddTagged_NotStarved(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts []rununtil.Option
+	}{
+		{"Sequential", nil},
+		{"Parallel", []rununtil.Option{rununtil.WithShutdownOrder(rununtil.OrderParallel)}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			hang := make(chan struct{})
+			defer close(hang)
+			var flushed atomic.Bool
+			critical := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+				return func() {
+					time.Sleep(20 * time.Millisecond)
+					flushed.Store(true)
+				}
+			})
+			slowBestEffort := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+				return func() { <-hang }
+			})
+			r := rununtil.New(append(tc.opts, rununtil.WithShutdownDeadline(100*time.Millisecond))...)
+			// the best-effort runner is started last, so without its tag it
+			// would be the first to be shut down
+			if err := r.AddTagged(rununtil.BestEffort, slowBestEffort); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			errChan := make(chan error)
+			go func() {
+				errChan <- r.Await(critical)
+			}()
+			err := helperKeepCancelling(t, r.Cancel, errChan)
+			if !errors.Is(err, rununtil.ErrShutdownDeadline) {
+				t.Fatalf("expected the best-effort shutdown to exceed the deadline, got: %v", err)
+			}
+			if !flushed.Load() {
+				t.Fatal("expected the critical shutdown to have completed within the deadline")
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package rununtil
+
+import (
+	"os"
+	"time"
+)
+
+// ShutdownProfile bundles the settings of a shutdown which WithSignalProfile
+// uses in place of the options when shutdown is begun by a particular kill
+// signal. Its zero values mean the same as they do for the options: no lame
+// duck delay, no shutdown timeout and OrderReverse.
+type ShutdownProfile struct {
+	// LameDuckDelay is used instead of WithLameDuckDelay.
+	LameDuckDelay time.Duration
+	// Timeout is used instead of WithShutdownTimeout.
+	Timeout time.Duration
+	// Order is used instead of WithShutdownOrder.
+	Order ShutdownOrder
+}
+
+// WithSignalProfile shuts down with the profile, rather than with the lame
+// duck delay, shutdown timeout and shutdown order of the options, when the
+// shutdown is begun by the kill signal sig. For example, SIGTERM from an
+// orchestrator can drain gracefully, while SIGINT from Ctrl+C stops straight
+// away:
+//
+//	rununtil.New(
+//		rununtil.WithLameDuckDelay(10*time.Second),
+//		rununtil.WithShutdownTimeout(20*time.Second),
+//		rununtil.WithSignalProfile(syscall.SIGINT, rununtil.ShutdownProfile{
+//			Timeout: time.Second,
+//			Order:   rununtil.OrderParallel,
+//		}),
+//	)
+//
+// A shutdown begun some other way, e.g. by being cancelled, always uses the
+// options.
+func WithSignalProfile(sig os.Signal, profile ShutdownProfile) Option {
+	return func(o *options) {
+		if o.profiles == nil {
+			o.profiles = make(map[os.Signal]ShutdownProfile)
+		}
+		o.profiles[sig] = profile
+	}
+}
+
+// useProfile switches the session to the profile of the kill signal which
+// stopped it, if it has one. Only the lame duck delay is switched straight
+// away, as the shutdown timeout may still be in use by a Replace until the
+// shutdown has begun, when applyProfile switches the rest.
+func (s *session) useProfile(sig os.Signal) {
+	profile, ok := s.opts.profiles[sig]
+	if !ok {
+		return
+	}
+	s.opts.logger.Infof("using the shutdown profile of signal %v", sig)
+	s.profile = &profile
+	s.opts.lameDuckDelay = profile.LameDuckDelay
+}
+
+// applyProfile switches the shutdown to the profile chosen by useProfile.
+func (s *session) applyProfile() {
+	if s.profile == nil {
+		return
+	}
+	s.opts.shutdownTimeout = s.profile.Timeout
+	s.opts.order = s.profile.Order
+}
@@ -0,0 +1,72 @@
+package rununtil_test
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRununtilWithSignalProfile(t *testing.T) {
+	hang := make(chan struct{})
+	defer close(hang)
+	signals := make(chan os.Signal)
+	r := rununtil.New(
+		rununtil.WithSignalSource(signals),
+		rununtil.WithLameDuckDelay(time.Hour),
+		rununtil.WithSignalProfile(syscall.SIGINT, rununtil.ShutdownProfile{
+			Timeout: 10 * time.Millisecond,
+			Order:   rununtil.OrderParallel,
+		}),
+	)
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(func() rununtil.ShutdownFunc {
+			return func() { <-hang }
+		})
+	}()
+	signals <- syscall.SIGINT
+	select {
+	case err := <-errChan:
+		if !errors.Is(err, rununtil.ErrShutdownTimeout) {
+			t.Fatalf("expected the profile's shutdown timeout, got: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the profile to have skipped the lame duck delay")
+	}
+}
+
+func TestRununtilWithSignalProfile_OtherSignal(t *testing.T) {
+	clock := newFakeClock()
+	logger := &fakeLogger{}
+	signals := make(chan os.Signal)
+	r := rununtil.New(
+		rununtil.WithClock(clock),
+		rununtil.WithLogger(logger),
+		rununtil.WithSignalSource(signals),
+		rununtil.WithLameDuckDelay(time.Hour),
+		rununtil.WithSignalProfile(syscall.SIGINT, rununtil.ShutdownProfile{}),
+	)
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await()
+	}()
+	signals <- syscall.SIGTERM
+	<-clock.waiting
+	if !logger.contains("lame duck for 1h0m0s") {
+		t.Fatalf("expected the options' lame duck delay to have been used, got: %v", logger.lines)
+	}
+
+	clock.advance(time.Hour)
+	if err := <-errChan; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if logger.contains("using the shutdown profile") {
+		t.Fatal("expected no profile to have been used")
+	}
+}
@@ -0,0 +1,35 @@
+package rununtil
+
+// WithProgress calls progress each time one of the shutdown functions has
+// completed, or timed out, with how many of the total have been done and the
+// description of the runner which is to be shut down next, e.g. "runner 3
+// (http)", or nothing once they have all been done. This gives operators
+// feedback during a long drain:
+//
+//	rununtil.WithProgress(func(done, total int, current string) {
+//		log.Printf("shutdown %d/%d complete, now draining %s", done, total, current)
+//	})
+//
+// It is called in order, from the go routine which executes the shutdown
+// functions, so it should return quickly. Since the shutdown functions are
+// only executed one at a time in a sequential shutdown, progress is never
+// called for an OrderParallel one.
+func WithProgress(progress func(done, total int, current string)) Option {
+	return func(o *options) {
+		o.progress = progress
+	}
+}
+
+// reportProgress tells the WithProgress function that done of the shutdowns,
+// which are being executed in the order, have completed.
+func reportProgress(done int, order []int, shutdowns []running, opts options) {
+	if opts.progress == nil {
+		return
+	}
+	current := ""
+	if done < len(order) {
+		next := order[done]
+		current = shutdowns[next].describe(next)
+	}
+	opts.progress(done, len(order), current)
+}
@@ -0,0 +1,69 @@
+package rununtil_test
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+type progressCall struct {
+	done, total int
+	current     string
+}
+
+// progressRecorder records all of the calls of a WithProgress function.
+type progressRecorder struct {
+	mux   sync.Mutex
+	calls []progressCall
+}
+
+func (rec *progressRecorder) progress(done, total int, current string) {
+	rec.mux.Lock()
+	defer rec.mux.Unlock()
+	rec.calls = append(rec.calls, progressCall{done: done, total: total, current: current})
+}
+
+// helperAwaitNamed awaits db, cache and http, added in that order, until the
+// Runner has been cancelled.
+func helperAwaitNamed(t *testing.T, r *rununtil.Runner) {
+	t.Helper()
+	rec := &orderRecorder{}
+	for _, name := range []string{"db", "cache", "http"} {
+		if err := r.AddNamed(name, nil, rec.runner(name)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await()
+	}()
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRununtilWithProgress(t *testing.T) {
+	rec := &progressRecorder{}
+	helperAwaitNamed(t, rununtil.New(rununtil.WithProgress(rec.progress)))
+
+	// the runners are shut down in reverse order
+	expected := []progressCall{
+		{done: 1, total: 3, current: "runner 1 (cache)"},
+		{done: 2, total: 3, current: "runner 0 (db)"},
+		{done: 3, total: 3},
+	}
+	if !reflect.DeepEqual(rec.calls, expected) {
+		t.Fatalf("expected the progress %+v, got: %+v", expected, rec.calls)
+	}
+}
+
+func TestRununtilWithProgress_Concurrent(t *testing.T) {
+	rec := &progressRecorder{}
+	helperAwaitNamed(t, rununtil.New(rununtil.WithProgress(rec.progress), rununtil.WithConcurrentShutdown()))
+
+	if len(rec.calls) != 0 {
+		t.Fatalf("expected progress not to be reported for a concurrent shutdown, got: %+v", rec.calls)
+	}
+}
@@ -0,0 +1,70 @@
+package rununtil
+
+import (
+	"context"
+	"time"
+)
+
+// defaultReadinessInterval is how often the readiness probe is polled if
+// WithReadinessProbe is given an interval which isn't positive.
+const defaultReadinessInterval = time.Second
+
+// WithReadinessProbe holds back declaring the service ready, e.g. sending
+// READY=1 with WithSystemdNotify or calling the onAllReady of
+// AwaitReadyRunners, until probe passes, so that being ready means that the
+// service's dependencies are available as well as its runners having started:
+//
+//	rununtil.WithReadinessProbe(func(ctx context.Context) error {
+//		return db.PingContext(ctx)
+//	}, time.Second)
+//
+// The probe is called once all of the runners have started, and then every
+// interval until it returns nil, while the await carries on listening for
+// kill signals. Its context is cancelled as soon as the await is told to
+// stop, so a kill signal while it is still probing shuts the service down
+// without it ever having been declared ready.
+func WithReadinessProbe(probe func(ctx context.Context) error, interval time.Duration) Option {
+	return func(o *options) {
+		o.readinessProbe = probe
+		o.readinessInterval = interval
+	}
+}
+
+// probeReadiness polls the readiness probe in a go routine, returning a
+// channel which is closed once it has passed, and a function which stops the
+// polling by cancelling the probe's context.
+func (s *session) probeReadiness() (<-chan struct{}, func()) {
+	interval := s.opts.readinessInterval
+	if interval <= 0 {
+		interval = defaultReadinessInterval
+	}
+	ctx, cancel := context.WithCancel(s.ctx)
+	ready := make(chan struct{})
+	go func() {
+		for {
+			err := s.opts.readinessProbe(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			if err == nil {
+				s.opts.logger.Infof("readiness probe passed")
+				close(ready)
+				return
+			}
+			s.opts.logger.Infof("readiness probe failed, retrying in %v: %v", interval, err)
+			select {
+			case <-s.opts.clock.After(interval):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ready, cancel
+}
+
+// declareReady calls the hooks which declare that the service is ready.
+func (s *session) declareReady() {
+	for _, started := range s.opts.onStarted {
+		started(s.opts.logger)
+	}
+}
@@ -0,0 +1,121 @@
+package rununtil_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRununtilWithReadinessProbe(t *testing.T) {
+	clock := newFakeClock()
+	logger := &fakeLogger{}
+	var probes atomic.Int64
+	probe := func(context.Context) error {
+		if probes.Add(1) < 3 {
+			return errors.New("database unreachable")
+		}
+		return nil
+	}
+	r := rununtil.New(rununtil.WithClock(clock), rununtil.WithLogger(logger), rununtil.WithReadinessProbe(probe, time.Second))
+
+	var hasBeenShutdown atomic.Bool
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(helperMakeFakeRunner(&hasBeenShutdown))
+	}()
+	for i := 0; i < 2; i++ {
+		<-clock.waiting
+		clock.advance(time.Second)
+	}
+	if !helperWaitFor(func() bool { return logger.contains("INFO: readiness probe passed") }) {
+		t.Fatalf("expected the readiness probe to have passed, got: %v", logger.lines)
+	}
+	if !logger.contains("INFO: readiness probe failed, retrying in 1s: database unreachable") {
+		t.Fatalf("expected the failures to have been logged, got: %v", logger.lines)
+	}
+	if n := probes.Load(); n != 3 {
+		t.Fatalf("expected the probe to have been called until it passed, got: %d", n)
+	}
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRununtilWithReadinessProbe_KillSignal(t *testing.T) {
+	clock := newFakeClock()
+	logger := &fakeLogger{}
+	signals := make(chan os.Signal, 1)
+	var probeCtx atomic.Value
+	probe := func(ctx context.Context) error {
+		probeCtx.Store(ctx)
+		return errors.New("migrations not applied")
+	}
+	r := rununtil.New(
+		rununtil.WithClock(clock),
+		rununtil.WithLogger(logger),
+		rununtil.WithSignalSource(signals),
+		rununtil.WithReadinessProbe(probe, time.Second),
+	)
+
+	var hasBeenShutdown atomic.Bool
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(helperMakeFakeRunner(&hasBeenShutdown))
+	}()
+	<-clock.waiting
+	signals <- syscall.SIGTERM
+	if err := <-errChan; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the runner to have been shut down while probing")
+	}
+	if ctx := probeCtx.Load().(context.Context); ctx.Err() == nil {
+		t.Fatal("expected the probe's context to have been cancelled")
+	}
+	if logger.contains("readiness probe passed") {
+		t.Fatalf("expected the service never to have been ready, got: %v", logger.lines)
+	}
+}
+
+func TestRununtilWithReadinessProbe_LameDuckDelay(t *testing.T) {
+	clock := newFakeClock()
+	signals := make(chan os.Signal, 1)
+	probing := make(chan struct{})
+	cancelled := make(chan struct{})
+	probe := func(ctx context.Context) error {
+		close(probing)
+		<-ctx.Done()
+		close(cancelled)
+		return ctx.Err()
+	}
+	r := rununtil.New(
+		rununtil.WithClock(clock),
+		rununtil.WithSignalSource(signals),
+		rununtil.WithLameDuckDelay(time.Hour),
+		rununtil.WithReadinessProbe(probe, time.Second),
+	)
+
+	var hasBeenShutdown atomic.Bool
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(helperMakeFakeRunner(&hasBeenShutdown))
+	}()
+	<-probing
+	signals <- syscall.SIGTERM
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("expected the probe's context to have been cancelled before the lame duck delay")
+	}
+	r.ForceNow()
+	if err := <-errChan; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package rununtil
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// ReadyRunnerFunc is a variant of RunnerFunc which calls ready once whatever it
+// runs is actually able to do its job, e.g. once an HTTP server is accepting
+// connections. Calling ready more than once has no further effect.
+type ReadyRunnerFunc func(ready func()) ShutdownFunc
+
+// AwaitKillSignalsReady runs the provided ReadyRunnerFuncs until it receives a
+// kill signal, SIGINT or SIGTERM, at which point it executes the graceful
+// shutdown functions. Once every ReadyRunnerFunc has called ready, onAllReady
+// is called in its own go routine, e.g. to log that the service is ready or
+// to let a test know that it can start sending requests. If shutdown begins
+// before all of them are ready then onAllReady is not called, and a runner
+// which never becomes ready does not stop the service from being shut down.
+func AwaitKillSignalsReady(onAllReady func(), runnerFuncs ...ReadyRunnerFunc) {
+	repanic(awaitReady(onAllReady, runnerFuncs, newOptions(nil)))
+}
+
+// AwaitReadyRunners is the same as AwaitKillSignalsReady, except that it is
+// configured by the options, in the same way as AwaitRunners, and returns any
+// errors that occurred during shutdown. With WithReadinessProbe, onAllReady
+// is held back until the probe has passed as well:
+//
+//	err := rununtil.AwaitReadyRunners(onAllReady, runners, rununtil.WithReadinessProbe(probe, time.Second))
+func AwaitReadyRunners(onAllReady func(), runners []ReadyRunnerFunc, opts ...Option) error {
+	return awaitReady(onAllReady, runners, newOptions(opts))
+}
+
+// awaitReady runs the ReadyRunnerFuncs, calling onAllReady once all of them
+// are ready and the session has declared the service ready, which it only
+// does after the readiness probe, if there is one, has passed.
+func awaitReady(onAllReady func(), runnerFuncs []ReadyRunnerFunc, opts options) error {
+	allReady := make(chan struct{})
+	notReady := int64(len(runnerFuncs))
+	if notReady == 0 {
+		close(allReady)
+	}
+	declared := make(chan struct{})
+	opts.onStarted = append(opts.onStarted, func(Logger) { close(declared) })
+
+	starters := make([]component, 0, len(runnerFuncs)+1)
+	for _, runner := range runnerFuncs {
+		runner := runner
+		var once sync.Once
+		ready := func() {
+			once.Do(func() {
+				if atomic.AddInt64(&notReady, -1) == 0 {
+					close(allReady)
+				}
+			})
+		}
+		wrapped := RunnerFunc(func() ShutdownFunc {
+			return runner(ready)
+		}).asStarter()
+		starters = append(starters, component{start: wrapped, origin: runner})
+	}
+	starters = append(starters, component{start: ContextRunnerFunc(func(ctx context.Context) ShutdownFunc {
+		go func() {
+			select {
+			case <-allReady:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case <-declared:
+				onAllReady()
+			case <-ctx.Done():
+			}
+		}()
+		return func() {}
+	}).asStarter()})
+
+	return awaitKillSignals(opts.signals, starters, opts)
+}
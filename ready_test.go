@@ -0,0 +1,110 @@
+package rununtil_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRununtilAwaitKillSignalsReady(t *testing.T) {
+	becomeReady := make(chan struct{})
+	readyRunner := rununtil.ReadyRunnerFunc(func(ready func()) rununtil.ShutdownFunc {
+		go func() {
+			<-becomeReady
+			ready()
+			ready()
+		}()
+		return func() {}
+	})
+	immediatelyReadyRunner := rununtil.ReadyRunnerFunc(func(ready func()) rununtil.ShutdownFunc {
+		ready()
+		return func() {}
+	})
+	allReady := make(chan struct{})
+
+	done := make(chan struct{})
+	go func() {
+		rununtil.AwaitKillSignalsReady(func() { close(allReady) }, readyRunner, immediatelyReadyRunner)
+		close(done)
+	}()
+
+	select {
+	case <-allReady:
+		t.Fatal("expected not to be ready until all of the runners are ready")
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(becomeReady)
+	<-allReady
+
+	helperCancelUntilDone(t, done)
+}
+
+func TestRununtilAwaitKillSignalsReady_NeverReady(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	neverReadyRunner := rununtil.ReadyRunnerFunc(func(ready func()) rununtil.ShutdownFunc {
+		return func() { hasBeenShutdown.Store(true) }
+	})
+
+	done := make(chan struct{})
+	go func() {
+		rununtil.AwaitKillSignalsReady(func() {
+			t.Error("expected onAllReady not to be called")
+		}, neverReadyRunner)
+		close(done)
+	}()
+
+	helperCancelUntilDone(t, done)
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the runner that never became ready to have been shutdown")
+	}
+}
+
+func TestRununtilAwaitReadyRunners_ReadinessProbe(t *testing.T) {
+	clock := newFakeClock()
+	var probes atomic.Int64
+	probe := func(context.Context) error {
+		if probes.Add(1) <= 2 {
+			return errors.New("database unreachable")
+		}
+		return nil
+	}
+	readyRunner := rununtil.ReadyRunnerFunc(func(ready func()) rununtil.ShutdownFunc {
+		ready()
+		return func() {}
+	})
+	runners := []rununtil.ReadyRunnerFunc{readyRunner}
+	allReady := make(chan struct{})
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- rununtil.AwaitReadyRunners(func() { close(allReady) }, runners,
+			rununtil.WithClock(clock),
+			rununtil.WithReadinessProbe(probe, time.Second),
+		)
+	}()
+	for i := 0; i < 2; i++ {
+		<-clock.waiting
+		select {
+		case <-allReady:
+			t.Fatalf("expected not to be ready while the probe is failing, after %d probes", probes.Load())
+		default:
+		}
+		clock.advance(time.Second)
+	}
+	select {
+	case <-allReady:
+	case <-time.After(time.Second):
+		t.Fatal("expected to be ready once the probe had passed")
+	}
+	if n := probes.Load(); n != 3 {
+		t.Fatalf("expected the probe to have been called until it passed, got: %d", n)
+	}
+
+	if err := helperCancelUntilDone(t, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
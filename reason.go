@@ -0,0 +1,58 @@
+package rununtil
+
+import (
+	"context"
+	"os"
+)
+
+// ReasonShutdownFunc is a variant of ShutdownFunc which is told why the await
+// is shutting down, so that its cleanup can depend on it, e.g. flushing its
+// buffers after SIGTERM but dropping them when the await has been cancelled.
+type ReasonShutdownFunc func(reason TerminationReason)
+
+// ReasonRunnerFunc is a variant of RunnerFunc whose shutdown function is a
+// ReasonShutdownFunc.
+type ReasonRunnerFunc func() ReasonShutdownFunc
+
+// reasonKey is the key of the TerminationReason in the shutdown's context.
+type reasonKey struct{}
+
+// asStarter converts the ReasonRunnerFunc into a starter whose shutdown is
+// given the reason from the shutdown's context.
+func (runner ReasonRunnerFunc) asStarter() starter {
+	return func(context.Context) (stopFunc, error) {
+		shutdown := runner()
+		if shutdown == nil {
+			return nil, nil
+		}
+		return func(ctx context.Context) error {
+			reason, _ := ctx.Value(reasonKey{}).(TerminationReason)
+			shutdown(reason)
+			return nil
+		}, nil
+	}
+}
+
+// AwaitKillSignalsReason runs the provided ReasonRunnerFuncs until it
+// receives one of the specified signals, or is cancelled, at which point it
+// executes their shutdown functions with the reason it is shutting down:
+//
+//	rununtil.AwaitKillSignalsReason(signals, func() rununtil.ReasonShutdownFunc {
+//		return func(reason rununtil.TerminationReason) {
+//			if reason.Kind == rununtil.ReasonSignal {
+//				buffer.Flush()
+//			}
+//		}
+//	})
+//
+// If a runner fails to start, the reason's Err is why, and its Kind is zero.
+// If a RunnerFunc panics it re-panics with a PanicError, in the same way as
+// AwaitKillSignals.
+func AwaitKillSignalsReason(signals []os.Signal, runnerFuncs ...ReasonRunnerFunc) {
+	repanic(awaitKillSignals(signals, starters(runnerFuncs), newOptions(nil)))
+}
+
+// reason returns why the session is shutting down.
+func (s *session) reason() TerminationReason {
+	return TerminationReason{Kind: s.stoppedBy, Signal: s.received, Err: s.startErr}
+}
@@ -0,0 +1,104 @@
+package rununtil_test
+
+import (
+	"errors"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+// helperMakeReasonRunner makes a ReasonRunnerFunc which passes the reason its
+// shutdown function is given to the channel.
+func helperMakeReasonRunner(reasons chan<- rununtil.TerminationReason) rununtil.ReasonRunnerFunc {
+	return func() rununtil.ReasonShutdownFunc {
+		return func(reason rununtil.TerminationReason) {
+			reasons <- reason
+		}
+	}
+}
+
+func TestRununtilAwaitKillSignalsReason_Signal(t *testing.T) {
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("unexpected error occurred: %v", err)
+	}
+	var sentSignal atomic.Bool
+	reasons := make(chan rununtil.TerminationReason, 1)
+
+	go helperSendSignal(t, p, &sentSignal, syscall.SIGINT, time.Millisecond)
+	rununtil.AwaitKillSignalsReason([]os.Signal{syscall.SIGINT}, helperMakeReasonRunner(reasons))
+
+	reason := <-reasons
+	if reason.Kind != rununtil.ReasonSignal || reason.Signal != syscall.SIGINT {
+		t.Fatalf("expected the shutdown to have been given SIGINT, got: %+v", reason)
+	}
+}
+
+func TestRununtilAwaitKillSignalsReason_CancelAll(t *testing.T) {
+	reasons := make(chan rununtil.TerminationReason, 1)
+
+	done := make(chan struct{})
+	go func() {
+		rununtil.AwaitKillSignalsReason([]os.Signal{syscall.SIGINT}, helperMakeReasonRunner(reasons))
+		close(done)
+	}()
+	helperCancelUntilDone(t, done)
+
+	reason := <-reasons
+	if reason.Kind != rununtil.ReasonCancelAll || reason.Signal != nil {
+		t.Fatalf("expected the shutdown to have been given CancelAll, got: %+v", reason)
+	}
+}
+
+func TestRununtilAwaitKillSignalsReason_StartFails(t *testing.T) {
+	reasons := make(chan rununtil.TerminationReason, 1)
+	panickingRunner := rununtil.ReasonRunnerFunc(func() rununtil.ReasonShutdownFunc {
+		panic("failed to connect")
+	})
+
+	recovered := make(chan interface{})
+	go func() {
+		defer func() {
+			recovered <- recover()
+		}()
+		rununtil.AwaitKillSignalsReason([]os.Signal{syscall.SIGINT}, helperMakeReasonRunner(reasons), panickingRunner)
+	}()
+	<-recovered
+
+	reason := <-reasons
+	var panicErr *rununtil.PanicError
+	if reason.Kind != 0 || !errors.As(reason.Err, &panicErr) {
+		t.Fatalf("expected the shutdown to have been given the panic, got: %+v", reason)
+	}
+}
+
+func TestRununtilAwaitKillSignalsReason_MaxLifetime(t *testing.T) {
+	reasons := make(chan rununtil.TerminationReason, 1)
+	opts := []rununtil.Option{rununtil.WithMaxLifetime(time.Millisecond)}
+
+	stoppedBy := rununtil.AwaitReason(nil, []os.Signal{syscall.SIGINT}, opts, helperMakeReasonRunner(reasons))
+	if stoppedBy.Kind != rununtil.ReasonMaxLifetime {
+		t.Fatalf("expected the await to have been stopped by the max lifetime, got: %+v", stoppedBy)
+	}
+	if reason := <-reasons; reason.Kind != rununtil.ReasonMaxLifetime || reason.Signal != nil {
+		t.Fatalf("expected the shutdown to have been given the max lifetime, got: %+v", reason)
+	}
+}
+
+func TestRununtilAwaitKillSignalsReason_Done(t *testing.T) {
+	reasons := make(chan rununtil.TerminationReason, 1)
+	done := make(chan struct{})
+	close(done)
+
+	stoppedBy := rununtil.AwaitReason(done, []os.Signal{syscall.SIGINT}, nil, helperMakeReasonRunner(reasons))
+	if stoppedBy.Kind != rununtil.ReasonDone {
+		t.Fatalf("expected the await to have been stopped by the done channel, got: %+v", stoppedBy)
+	}
+	if reason := <-reasons; reason.Kind != rununtil.ReasonDone || reason.Signal != nil {
+		t.Fatalf("expected the shutdown to have been given the done channel, got: %+v", reason)
+	}
+}
@@ -0,0 +1,40 @@
+package rununtil
+
+import (
+	"fmt"
+	"os"
+)
+
+// WithReExecOnSignal makes sig a kill signal which, once the shutdown has
+// completed, re-executes the current binary with the same arguments and
+// environment, so that a daemon can be restarted as a fresh process, rather
+// than being reloaded in place:
+//
+//	rununtil.New(rununtil.WithReExecOnSignal(syscall.SIGHUP))
+//
+// Nothing is passed on to the new process, including any listeners, so it
+// starts from scratch. If the re-exec fails it is logged and the await returns
+// the error, and it isn't supported on Windows, which can't replace a running
+// process.
+func WithReExecOnSignal(sig os.Signal) Option {
+	return func(o *options) {
+		o.reExecSignal = sig
+	}
+}
+
+// reExec replaces the process with a new one running the same binary.
+func (s *session) reExec() error {
+	path, err := os.Executable()
+	if err != nil {
+		s.opts.logger.Errorf("failed to find the binary to re-exec: %v", err)
+		return fmt.Errorf("re-exec: %w", err)
+	}
+	s.opts.logger.Infof("re-executing %s", path)
+	// the new process replaces this one, so nothing else will flush
+	s.flush()
+	if err := execProcess(path, os.Args, os.Environ()); err != nil {
+		s.opts.logger.Errorf("failed to re-exec %s: %v", path, err)
+		return fmt.Errorf("re-exec of %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package rununtil
+
+import "syscall"
+
+// execProcess replaces the process with the binary, and is replaced by the
+// tests so that they don't actually re-exec themselves.
+var execProcess = syscall.Exec
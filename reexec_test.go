@@ -0,0 +1,94 @@
+package rununtil_test
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"sync/atomic"
+	"syscall"
+	"testing"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+type execCall struct {
+	argv0 string
+	argv  []string
+}
+
+// helperAwaitReExec awaits with WithReExecOnSignal(SIGHUP) until sig is
+// received, returning what it tried to exec, if anything, and what the await
+// returned.
+func helperAwaitReExec(t *testing.T, sig os.Signal, execErr error, opts ...rununtil.Option) ([]execCall, error) {
+	t.Helper()
+	var calls []execCall
+	defer rununtil.SetExec(func(argv0 string, argv []string, envv []string) error {
+		calls = append(calls, execCall{argv0: argv0, argv: argv})
+		return execErr
+	})()
+	var hasBeenShutdown atomic.Bool
+	signals := make(chan os.Signal)
+	opts = append([]rununtil.Option{
+		rununtil.WithSignals(syscall.SIGTERM),
+		rununtil.WithSignalSource(signals),
+		rununtil.WithReExecOnSignal(syscall.SIGHUP),
+	}, opts...)
+	r := rununtil.New(opts...)
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(helperMakeFakeRunner(&hasBeenShutdown))
+	}()
+	signals <- sig
+	err := <-errChan
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the shutdown function to have been called")
+	}
+	return calls, err
+}
+
+func TestRununtilWithReExecOnSignal(t *testing.T) {
+	calls, err := helperAwaitReExec(t, syscall.SIGHUP, nil)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	executable, _ := os.Executable()
+	if len(calls) != 1 || calls[0].argv0 != executable || !reflect.DeepEqual(calls[0].argv, os.Args) {
+		t.Fatalf("expected to have re-executed %s with %v, got: %+v", executable, os.Args, calls)
+	}
+}
+
+func TestRununtilWithReExecOnSignal_OtherSignal(t *testing.T) {
+	calls, err := helperAwaitReExec(t, syscall.SIGTERM, nil)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(calls) != 0 {
+		t.Fatalf("expected not to have re-executed, got: %+v", calls)
+	}
+}
+
+func TestRununtilWithReExecOnSignal_Fails(t *testing.T) {
+	execErr := errors.New("permission denied")
+	_, err := helperAwaitReExec(t, syscall.SIGHUP, execErr)
+
+	if !errors.Is(err, execErr) {
+		t.Fatalf("expected the re-exec error, got: %v", err)
+	}
+}
+
+func TestRununtilWithReExecOnSignal_FailsFlushesOnce(t *testing.T) {
+	var flushes atomic.Int64
+	_, err := helperAwaitReExec(t, syscall.SIGHUP, errors.New("permission denied"),
+		rununtil.WithFinalFlush(func() { flushes.Add(1) }),
+	)
+
+	if err == nil {
+		t.Fatal("expected the re-exec error")
+	}
+	if n := flushes.Load(); n != 1 {
+		t.Fatalf("expected the final flush to have been called once, got: %d", n)
+	}
+}
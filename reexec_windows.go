@@ -0,0 +1,9 @@
+package rununtil
+
+import "errors"
+
+// execProcess always fails, since Windows has no way to replace the running
+// process with another, and is replaced by the tests.
+var execProcess = func(argv0 string, argv []string, envv []string) error {
+	return errors.New("re-exec is not supported on windows")
+}
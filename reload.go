@@ -0,0 +1,24 @@
+package rununtil
+
+import (
+	"fmt"
+	"os"
+)
+
+// AwaitKillSignalsWithReload runs the provided RunnerFuncs until one of the
+// kill signals has been received, at which point it executes the graceful
+// shutdown functions. Whenever one of the reload signals, such as SIGHUP, is
+// received it calls onReload instead and carries on running. The onReload
+// function is called synchronously, so no other signal is handled until it
+// returns. It panics if a signal is both a kill signal and a reload signal.
+func AwaitKillSignalsWithReload(killSignals, reloadSignals []os.Signal, onReload func(), runnerFuncs ...RunnerFunc) {
+	opts := newOptions(nil)
+	opts.actions = make(map[os.Signal][]func())
+	for _, sig := range reloadSignals {
+		if containsSignal(killSignals, sig) {
+			panic(fmt.Sprintf("rununtil: signal %v cannot be both a kill signal and a reload signal", sig))
+		}
+		opts.actions[sig] = []func(){onReload}
+	}
+	repanic(awaitKillSignals(killSignals, starters(runnerFuncs), opts))
+}
@@ -0,0 +1,71 @@
+package rununtil_test
+
+import (
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRununtilAwaitKillSignalsWithReload(t *testing.T) {
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("Unexpected error when finding process: %v", err)
+	}
+
+	var hasBeenShutdown atomic.Bool
+	started := make(chan struct{})
+	runner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		close(started)
+		return helperMakeFakeRunner(&hasBeenShutdown)()
+	})
+	reloaded := make(chan struct{})
+	onReload := func() {
+		reloaded <- struct{}{}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		rununtil.AwaitKillSignalsWithReload(
+			[]os.Signal{syscall.SIGINT},
+			[]os.Signal{syscall.SIGHUP},
+			onReload,
+			runner,
+		)
+		close(done)
+	}()
+	<-started
+
+	for idx := 0; idx < 3; idx++ {
+		if err := p.Signal(syscall.SIGHUP); err != nil {
+			t.Fatalf("unexpected error occurred: %v", err)
+		}
+		<-reloaded
+		if hasBeenShutdown.Load() {
+			t.Fatal("expected a reload signal not to shut down the runner")
+		}
+	}
+
+	if err := p.Signal(syscall.SIGINT); err != nil {
+		t.Fatalf("unexpected error occurred: %v", err)
+	}
+	<-done
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the kill signal to shut down the runner")
+	}
+}
+
+func TestRununtilAwaitKillSignalsWithReload_PanicsOnOverlap(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected overlapping kill and reload signals to panic")
+		}
+	}()
+	rununtil.AwaitKillSignalsWithReload(
+		[]os.Signal{syscall.SIGINT, syscall.SIGHUP},
+		[]os.Signal{syscall.SIGHUP},
+		func() {},
+	)
+}
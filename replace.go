@@ -0,0 +1,97 @@
+package rununtil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNoSuchRunner is returned when there is no runner with the name that was
+// given.
+var ErrNoSuchRunner = errors.New("no such runner")
+
+// Replace shuts down the runner which was added with AddNamed under the name,
+// and then starts newRunner in its place, so that a single subsystem can be
+// recreated, e.g. to pick up new TLS certificates, without stopping the whole
+// process:
+//
+//	err := r.Replace("api", rununtil.HTTPServerRunner(newServer))
+//
+// The replacement keeps the name, dependencies and position of the runner it
+// replaces, and its ShutdownFunc is executed during graceful shutdown instead.
+// The old ShutdownFunc is given the WithShutdownTimeout timeout, and the new
+// runner is started even if it fails. Before the Runner's first await the
+// RunnerFunc which has been added is simply swapped for newRunner. It returns
+// ErrShuttingDown if shutdown has already begun, and an error wrapping
+// ErrNoSuchRunner if no runner has been added under the name.
+func (r *Runner) Replace(name string, newRunner RunnerFunc) error {
+	r.mux.Lock()
+	s := r.current
+	if s == nil {
+		defer r.mux.Unlock()
+		for idx, c := range r.pending {
+			if c.name == name {
+				r.pending[idx].start = newRunner.asStarter()
+				r.pending[idx].origin = newRunner
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: %q", ErrNoSuchRunner, name)
+	}
+	r.mux.Unlock()
+	return s.replace(name, newRunner)
+}
+
+// replace shuts down the named component and starts the runner in its place.
+func (s *session) replace(name string, runner RunnerFunc) error {
+	s.mux.Lock()
+	if s.stopping {
+		s.mux.Unlock()
+		return ErrShuttingDown
+	}
+	idx := -1
+	for i, c := range s.running {
+		if c.name == name {
+			idx = i
+		}
+	}
+	if idx < 0 {
+		s.mux.Unlock()
+		return fmt.Errorf("%w: %q", ErrNoSuchRunner, name)
+	}
+	if s.replacing[name] {
+		s.mux.Unlock()
+		return fmt.Errorf("runner %q is already being replaced", name)
+	}
+	if s.replacing == nil {
+		s.replacing = make(map[string]bool)
+	}
+	s.replacing[name] = true
+	old := s.running[idx]
+	s.adding.Add(1)
+	s.mux.Unlock()
+	defer s.adding.Done()
+
+	s.opts.logger.Infof("replacing %s", old.describe(idx))
+	var errs []error
+	if err := runShutdown(context.Background(), old.stop, s.opts.shutdownTimeout, s.opts.clock); err != nil {
+		errs = append(errs, fmt.Errorf("shutdown of %s: %w", old.describe(idx), err))
+	}
+	c := old.component
+	c.start = runner.asStarter()
+	c.origin = runner
+	replacement, err := s.launch(idx, c)
+	if err != nil {
+		s.failed(idx, c, err)
+		errs = append(errs, err)
+		// the old runner has already been shut down, so there is nothing
+		// left to shut down
+		replacement = running{component: c, stop: nopStop}
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.running[idx] = replacement
+	delete(s.replacing, name)
+	return errors.Join(errs...)
+}
@@ -0,0 +1,143 @@
+package rununtil_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+// helperAwaitStarted runs the Runner's await in a go routine, returning once it
+// has begun along with the channel which its error is sent on.
+func helperAwaitStarted(t *testing.T, r *rununtil.Runner) <-chan error {
+	t.Helper()
+	started := make(chan struct{})
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- r.Await(rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+			close(started)
+			return func() {}
+		}))
+	}()
+	<-started
+	return errChan
+}
+
+func TestRunner_Replace(t *testing.T) {
+	rec := &orderRecorder{}
+	var mux sync.Mutex
+	var timed []string
+	r := rununtil.New(rununtil.WithShutdownTiming(func(name string, _ time.Duration, _ error) {
+		mux.Lock()
+		defer mux.Unlock()
+		timed = append(timed, name)
+	}))
+	errChan := helperAwaitStarted(t, r)
+	if err := r.AddNamed("db", nil, rec.runner("db")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.AddNamed("api", []string{"db"}, rec.runner("old api")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := r.Replace("api", rec.runner("new api")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rec.mux.Lock()
+	if len(rec.shutdown) != 1 || rec.shutdown[0] != "old api" {
+		t.Fatalf("expected only the old api to have been shut down, got: %v", rec.shutdown)
+	}
+	helperAssertBefore(t, rec.started, "old api", "new api")
+	rec.mux.Unlock()
+
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rec.shutdown) != 3 {
+		t.Fatalf("expected the old api to have been shut down only once, got: %v", rec.shutdown)
+	}
+	// the replacement keeps the dependencies of the runner it replaced
+	helperAssertBefore(t, rec.shutdown, "new api", "db")
+	// and its label, in the logs and reports of the shutdown
+	mux.Lock()
+	defer mux.Unlock()
+	if len(timed) != 3 || timed[0] != "runner 2 (api)" {
+		t.Fatalf("expected the replacement to have been labelled as the api, got: %v", timed)
+	}
+}
+
+func TestRunner_Replace_BeforeAwait(t *testing.T) {
+	rec := &orderRecorder{}
+	r := rununtil.New()
+	if err := r.AddNamed("api", nil, rec.runner("old api")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.Replace("api", rec.runner("new api")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await()
+	}()
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rec.started) != 1 || rec.started[0] != "new api" {
+		t.Fatalf("expected only the new api to have been started, got: %v", rec.started)
+	}
+}
+
+func TestRunner_Replace_NoSuchRunner(t *testing.T) {
+	rec := &orderRecorder{}
+	r := rununtil.New()
+	if err := r.Replace("api", rec.runner("api")); !errors.Is(err, rununtil.ErrNoSuchRunner) {
+		t.Fatalf("expected a no such runner error before the await, got: %v", err)
+	}
+
+	errChan := helperAwaitStarted(t, r)
+	if err := r.Replace("api", rec.runner("api")); !errors.Is(err, rununtil.ErrNoSuchRunner) {
+		t.Fatalf("expected a no such runner error during the await, got: %v", err)
+	}
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRunner_Replace_ShuttingDown(t *testing.T) {
+	rec := &orderRecorder{}
+	r := rununtil.New()
+	errChan := helperAwaitStarted(t, r)
+	if err := r.AddNamed("api", nil, rec.runner("old api")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := r.Replace("api", rec.runner("new api")); !errors.Is(err, rununtil.ErrShuttingDown) {
+		t.Fatalf("expected a shutting down error, got: %v", err)
+	}
+}
+
+func TestRunner_Replace_Panics(t *testing.T) {
+	rec := &orderRecorder{}
+	r := rununtil.New()
+	errChan := helperAwaitStarted(t, r)
+	if err := r.AddNamed("api", nil, rec.runner("old api")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var panicErr *rununtil.PanicError
+	if err := r.Replace("api", helperMakePanickingRunner("boom")); !errors.As(err, &panicErr) {
+		t.Fatalf("expected a panic error, got: %v", err)
+	}
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rec.shutdown) != 1 {
+		t.Fatalf("expected the old api to have been shut down only once, got: %v", rec.shutdown)
+	}
+}
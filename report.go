@@ -0,0 +1,119 @@
+package rununtil
+
+import (
+	"errors"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ShutdownReport summarises how the graceful shutdown went, e.g. for logging
+// after the fact or for asserting in tests that a runner shut down in time.
+type ShutdownReport struct {
+	// Runners has the result of each runner's shutdown, in the order the
+	// runners were provided.
+	Runners []RunnerResult
+	// TotalDuration is how long the whole shutdown took.
+	TotalDuration time.Duration
+	// Signal is the kill signal which began the shutdown, or nil if it was
+	// begun some other way, e.g. by being cancelled.
+	Signal os.Signal
+	// LeaderReleaseErr is the error that the WithLeaderRelease function
+	// returned, if any.
+	LeaderReleaseErr error
+}
+
+// RunnerResult is the result of shutting down a single runner.
+type RunnerResult struct {
+	// Index is the index of the runner, in the order the runners were
+	// provided.
+	Index int
+	// Name is the name of the runner or, if it wasn't given one, the name of
+	// the func it was made from, e.g. main.NewHTTPRunner.func1.
+	Name string
+	// Duration is how long the runner's shutdown function took, or how long
+	// it was waited for if it timed out.
+	Duration time.Duration
+	// Err is the error that the shutdown function returned, if any.
+	Err error
+	// TimedOut is set if the shutdown function did not complete within the
+	// shutdown timeout or deadline.
+	TimedOut bool
+}
+
+// AwaitKillSignalsResult is the same as AwaitKillSignalsWithOptions, except
+// that it returns a ShutdownReport, along with any errors that occurred, once
+// shutdown has completed:
+//
+//	report, err := rununtil.AwaitKillSignalsResult(signals, opts, runner)
+//	for _, result := range report.Runners {
+//		log.Printf("runner %d shut down in %v", result.Index, result.Duration)
+//	}
+func AwaitKillSignalsResult(signals []os.Signal, opts []Option, runnerFuncs ...RunnerFunc) (ShutdownReport, error) {
+	reporter := &shutdownReporter{}
+	o := newOptions(opts)
+	o.reporter = reporter
+	err := awaitKillSignals(signals, starters(runnerFuncs), o)
+	return reporter.report(), err
+}
+
+// AwaitKillSignalsFull is the batteries included variant of AwaitKillSignals,
+// which is configured entirely by the options, including which kill signals it
+// awaits (SIGINT and SIGTERM unless WithSignals is given), and returns a
+// ShutdownReport along with any errors that occurred during shutdown:
+//
+//	report, err := rununtil.AwaitKillSignalsFull([]rununtil.Option{
+//		rununtil.WithShutdownTimeout(5 * time.Second),
+//		rununtil.WithLameDuckDelay(10 * time.Second),
+//		rununtil.WithLogger(logger),
+//	}, NewRunner(logger))
+func AwaitKillSignalsFull(opts []Option, runnerFuncs ...RunnerFunc) (ShutdownReport, error) {
+	return AwaitKillSignalsResult(newOptions(opts).signals, opts, runnerFuncs...)
+}
+
+// shutdownReporter collects the results of the runners' shutdowns, which may
+// be executed concurrently.
+type shutdownReporter struct {
+	mux     sync.Mutex
+	results []RunnerResult
+	total   time.Duration
+	signal  os.Signal
+	// leaderRelease is the error of the WithLeaderRelease function.
+	leaderRelease error
+}
+
+func (r *shutdownReporter) addResult(idx int, name string, duration time.Duration, err error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.results = append(r.results, RunnerResult{
+		Index:    idx,
+		Name:     name,
+		Duration: duration,
+		Err:      err,
+		TimedOut: errors.Is(err, ErrShutdownTimeout) || errors.Is(err, ErrShutdownDeadline),
+	})
+}
+
+func (r *shutdownReporter) releasedLeadership(err error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.leaderRelease = err
+}
+
+func (r *shutdownReporter) finish(sig os.Signal, total time.Duration) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.signal = sig
+	r.total = total
+}
+
+func (r *shutdownReporter) report() ShutdownReport {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	results := append([]RunnerResult(nil), r.results...)
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Index < results[j].Index
+	})
+	return ShutdownReport{Runners: results, TotalDuration: r.total, Signal: r.signal, LeaderReleaseErr: r.leaderRelease}
+}
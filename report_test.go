@@ -0,0 +1,91 @@
+package rununtil_test
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+type reportResult struct {
+	report rununtil.ShutdownReport
+	err    error
+}
+
+func TestRununtilAwaitKillSignalsResult(t *testing.T) {
+	delay := 10 * time.Millisecond
+	hang := make(chan struct{})
+	defer close(hang)
+	quickRunner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		return func() {}
+	})
+	slowRunner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		return func() { time.Sleep(delay) }
+	})
+	hangingRunner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		return func() { <-hang }
+	})
+	opts := []rununtil.Option{rununtil.WithShutdownTimeout(50 * time.Millisecond)}
+
+	results := make(chan reportResult)
+	go func() {
+		report, err := rununtil.AwaitKillSignalsResult([]os.Signal{syscall.SIGINT}, opts, quickRunner, slowRunner, hangingRunner)
+		results <- reportResult{report: report, err: err}
+	}()
+	result := helperCancelUntilDone(t, results)
+
+	if !errors.Is(result.err, rununtil.ErrShutdownTimeout) {
+		t.Fatalf("expected a shutdown timeout error, got: %v", result.err)
+	}
+	runners := result.report.Runners
+	if len(runners) != 3 {
+		t.Fatalf("expected a result for each runner, got: %+v", runners)
+	}
+	for idx, runner := range runners {
+		if runner.Index != idx {
+			t.Fatalf("expected the results to be in order, got: %+v", runners)
+		}
+	}
+	if runners[0].Err != nil || runners[0].TimedOut {
+		t.Fatalf("expected the quick runner to have shut down, got: %+v", runners[0])
+	}
+	if runners[1].Duration < delay {
+		t.Fatalf("expected the slow runner to take at least %v, got: %v", delay, runners[1].Duration)
+	}
+	if !runners[2].TimedOut || !errors.Is(runners[2].Err, rununtil.ErrShutdownTimeout) {
+		t.Fatalf("expected the hanging runner to have timed out, got: %+v", runners[2])
+	}
+	if result.report.TotalDuration < runners[1].Duration+runners[2].Duration {
+		t.Fatalf("expected the total duration to cover every runner, got: %v", result.report.TotalDuration)
+	}
+}
+
+func TestRununtilAwaitKillSignalsFull(t *testing.T) {
+	signals := make(chan os.Signal)
+	opts := []rununtil.Option{
+		rununtil.WithSignalSource(signals),
+		rununtil.WithSignals(syscall.SIGHUP),
+		rununtil.WithShutdownOrder(rununtil.OrderForward),
+	}
+	quickRunner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		return func() {}
+	})
+
+	results := make(chan reportResult)
+	go func() {
+		report, err := rununtil.AwaitKillSignalsFull(opts, quickRunner, quickRunner)
+		results <- reportResult{report: report, err: err}
+	}()
+	signals <- syscall.SIGHUP
+	result := <-results
+
+	if result.err != nil {
+		t.Fatalf("unexpected error: %v", result.err)
+	}
+	if len(result.report.Runners) != 2 {
+		t.Fatalf("expected a result for each runner, got: %+v", result.report.Runners)
+	}
+}
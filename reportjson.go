@@ -0,0 +1,71 @@
+package rununtil
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// WithReportJSON writes the ShutdownReport to w as a single line of JSON once
+// shutdown has completed, so that it can be picked up by log aggregation
+// without having to parse the Logger's lines, e.g.
+//
+//	{"signal":"interrupt","total_duration_ms":12.5,"runners":[{"index":0,"name":"http","duration_ms":12.4}]}
+//
+// The durations are in milliseconds, the signal is left out if shutdown
+// wasn't begun by one, and each runner only has an error if its shutdown
+// function failed.
+func WithReportJSON(w io.Writer) Option {
+	return func(o *options) {
+		o.reportJSON = w
+	}
+}
+
+type jsonReport struct {
+	Signal             string             `json:"signal,omitempty"`
+	TotalDurationMS    float64            `json:"total_duration_ms"`
+	Runners            []jsonRunnerResult `json:"runners"`
+	LeaderReleaseError string             `json:"leader_release_error,omitempty"`
+}
+
+type jsonRunnerResult struct {
+	Index      int     `json:"index"`
+	Name       string  `json:"name,omitempty"`
+	DurationMS float64 `json:"duration_ms"`
+	Error      string  `json:"error,omitempty"`
+	TimedOut   bool    `json:"timed_out,omitempty"`
+}
+
+// writeReportJSON writes the report to w as a line of JSON, or as a plain
+// line if it can't be marshalled, so that the summary is never lost.
+func writeReportJSON(w io.Writer, report ShutdownReport) error {
+	out := jsonReport{
+		TotalDurationMS: float64(report.TotalDuration.Microseconds()) / 1000,
+		Runners:         make([]jsonRunnerResult, 0, len(report.Runners)),
+	}
+	if report.Signal != nil {
+		out.Signal = report.Signal.String()
+	}
+	if report.LeaderReleaseErr != nil {
+		out.LeaderReleaseError = report.LeaderReleaseErr.Error()
+	}
+	for _, result := range report.Runners {
+		runner := jsonRunnerResult{
+			Index:      result.Index,
+			Name:       result.Name,
+			DurationMS: float64(result.Duration.Microseconds()) / 1000,
+			TimedOut:   result.TimedOut,
+		}
+		if result.Err != nil {
+			runner.Error = result.Err.Error()
+		}
+		out.Runners = append(out.Runners, runner)
+	}
+	line, err := json.Marshal(out)
+	if err != nil {
+		_, err = fmt.Fprintf(w, "shutdown of %d runners completed in %v (the report could not be marshalled: %v)\n", len(report.Runners), report.TotalDuration, err)
+		return err
+	}
+	_, err = w.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,108 @@
+package rununtil_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+type jsonReport struct {
+	Signal          string  `json:"signal"`
+	TotalDurationMS float64 `json:"total_duration_ms"`
+	Runners         []struct {
+		Index      int     `json:"index"`
+		Name       string  `json:"name"`
+		DurationMS float64 `json:"duration_ms"`
+		Error      string  `json:"error"`
+		TimedOut   bool    `json:"timed_out"`
+	} `json:"runners"`
+}
+
+func TestRununtilWithReportJSON(t *testing.T) {
+	hang := make(chan struct{})
+	defer close(hang)
+	var buf bytes.Buffer
+	signals := make(chan os.Signal)
+	r := rununtil.New(
+		rununtil.WithSignalSource(signals),
+		rununtil.WithShutdownTimeout(10*time.Millisecond),
+		rununtil.WithReportJSON(&buf),
+	)
+	if err := r.AddNamed("http", nil, func() rununtil.ShutdownFunc {
+		return func() {}
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(func() rununtil.ShutdownFunc {
+			return func() { <-hang }
+		})
+	}()
+	signals <- syscall.SIGTERM
+	if err := <-errChan; !errors.Is(err, rununtil.ErrShutdownTimeout) {
+		t.Fatalf("expected a shutdown timeout error, got: %v", err)
+	}
+
+	if bytes.Count(buf.Bytes(), []byte("\n")) != 1 {
+		t.Fatalf("expected a single line, got: %q", buf.String())
+	}
+	var report jsonReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("expected the report to be JSON, got %q: %v", buf.String(), err)
+	}
+	if report.Signal != syscall.SIGTERM.String() {
+		t.Fatalf("expected the signal to be %q, got: %q", syscall.SIGTERM.String(), report.Signal)
+	}
+	if len(report.Runners) != 2 {
+		t.Fatalf("expected a result for each runner, got: %+v", report.Runners)
+	}
+	named := false
+	for _, runner := range report.Runners {
+		if runner.Name == "http" {
+			named = true
+			if runner.Error != "" || runner.TimedOut {
+				t.Fatalf("expected the named runner to have shut down, got: %+v", runner)
+			}
+		} else if !runner.TimedOut || runner.Error == "" {
+			t.Fatalf("expected the hanging runner to have timed out, got: %+v", runner)
+		}
+	}
+	if !named {
+		t.Fatalf("expected the runner's name to have been reported, got: %+v", report.Runners)
+	}
+	if report.TotalDurationMS < 10 {
+		t.Fatalf("expected the shutdown to have taken at least the timeout, got: %vms", report.TotalDurationMS)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestRununtilWithReportJSON_WriteFails(t *testing.T) {
+	logger := &fakeLogger{}
+	r := rununtil.New(rununtil.WithLogger(logger), rununtil.WithReportJSON(failingWriter{}))
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(func() rununtil.ShutdownFunc {
+			return func() {}
+		})
+	}()
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !logger.contains("failed to write the shutdown report: disk full") {
+		t.Fatal("expected the failed write to have been logged")
+	}
+}
@@ -0,0 +1,19 @@
+package rununtil
+
+import "errors"
+
+// ErrNoRunners is returned by an await which was configured with
+// WithRequireRunners but wasn't given any runners to run.
+var ErrNoRunners = errors.New("no runners to await")
+
+// WithRequireRunners makes an await return ErrNoRunners straight away, rather
+// than blocking until a kill signal is received, if it hasn't been given any
+// runners, neither directly nor with Runner.Add before it began. This stops a
+// configuration mistake, such as an empty slice of runners, from deploying a
+// service which does nothing but stays up. It shouldn't be used with a Runner
+// whose runners are only added once its await has begun.
+func WithRequireRunners() Option {
+	return func(o *options) {
+		o.requireRunners = true
+	}
+}
@@ -0,0 +1,63 @@
+package rununtil_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRununtilWithRequireRunners(t *testing.T) {
+	r := rununtil.New(rununtil.WithRequireRunners())
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await()
+	}()
+	select {
+	case err := <-errChan:
+		if !errors.Is(err, rununtil.ErrNoRunners) {
+			t.Fatalf("expected a no runners error, got: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the await to have returned straight away")
+	}
+}
+
+func TestRununtilWithRequireRunners_Added(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	r := rununtil.New(rununtil.WithRequireRunners())
+	if err := r.Add(helperMakeFakeRunner(&hasBeenShutdown)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await()
+	}()
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the added runner to have been run and shut down")
+	}
+}
+
+func TestRununtilNoRunners(t *testing.T) {
+	r := rununtil.New()
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await()
+	}()
+	select {
+	case err := <-errChan:
+		t.Fatalf("expected the await to block without any runners, got: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package rununtil
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrShuttingDown is returned when a runner is added to a Runner whose
+// shutdown has already begun.
+var ErrShuttingDown = errors.New("shutdown has already begun")
+
+// Runner awaits kill signals for its own set of runners. Each Runner has its
+// own canceller, so cancelling one Runner does not affect the awaits of any
+// other Runner. This makes it possible to run, and test, several independent
+// servers in the same process.
+//
+// A Runner created with New is also independent of the package level
+// functions: CancelAll, CancelAllReason, CancelKey and ForceNow never affect
+// its awaits, and ShuttingDown doesn't report on them. A library can embed one
+// without another part of the binary being able to stop it, other than with a
+// kill signal, or through its parent if it was created with NewChild.
+type Runner struct {
+	canceller *canceller
+	opts      []Option
+
+	mux sync.Mutex
+	// current is the Runner's most recent await, which added runners are
+	// added to.
+	current *session
+	// pending are the runners that were added before the Runner's first
+	// await, which they will be started by.
+	pending []component
+	// graph holds the dependencies of every runner that has been added with a
+	// name.
+	graph map[string][]string
+	// sessions are the Runner's awaits which have not yet finished shutting
+	// down.
+	sessions map[*session]struct{}
+	// checks are the HealthChecks of the HealthRunnerFuncs which are
+	// running.
+	checks []*HealthCheck
+	// actions are run when their signal is received, and are registered
+	// with OnSignal.
+	actions map[os.Signal][]func()
+	// shuttingDown is set once one of the Runner's awaits has begun shutting
+	// down, and cleared when the next one begins.
+	shuttingDown atomic.Bool
+	// children are the Runners created with NewChild, which are cancelled
+	// along with the Runner.
+	children []*Runner
+	// triggered is set by TriggerShutdown, after which every await is
+	// cancelled as soon as it begins.
+	triggered bool
+	// done is closed once the triggered shutdown has finished, and
+	// doneClosed records that it has been.
+	done       chan struct{}
+	doneClosed bool
+}
+
+// defaultRunner is the Runner used by the package level functions, such as
+// AwaitKillSignal and CancelAll.
+var defaultRunner = &Runner{canceller: &globalCanceller}
+
+// New creates a Runner which is configured by the options.
+func New(opts ...Option) *Runner {
+	return &Runner{
+		canceller: newCanceller(),
+		opts:      opts,
+	}
+}
+
+// Await runs the provided RunnerFuncs until the Runner receives one of its kill
+// signals, SIGINT or SIGTERM unless configured otherwise with WithSignals, or
+// until it is cancelled, at which point it executes the graceful shutdown
+// functions. It returns any errors that occurred during shutdown. With no
+// RunnerFuncs, and none added with Add, it simply blocks until it is stopped
+// unless the Runner was created with WithRequireRunners.
+func (r *Runner) Await(runnerFuncs ...RunnerFunc) error {
+	opts := newOptions(r.opts)
+	return r.await(opts.signals, starters(runnerFuncs), opts)
+}
+
+// Cancel stops all of the Runner's awaits, and those of its descendants, in the
+// same way that a kill signal would stop them.
+func (r *Runner) Cancel() {
+	r.CancelReason("")
+}
+
+// CancelReason is the same as Cancel, except that the reason is logged by each
+// of the awaits and included in the cause of their context.
+func (r *Runner) CancelReason(reason string) {
+	r.canceller.cancelAll(reason)
+	for _, child := range r.childRunners() {
+		child.CancelReason(reason)
+	}
+}
+
+// Stop stops all of the Runner's awaits, and those of its descendants, in the
+// same way as Cancel, and then waits for them to finish shutting down, unlike
+// Cancel which returns straight away. It returns the combined errors of their
+// shutdowns, or the context's error if it is done before they have all
+// finished:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	if err := r.Stop(ctx); err != nil {
+//		t.Fatal(err)
+//	}
+func (r *Runner) Stop(ctx context.Context) error {
+	sessions := r.awaiting()
+	r.Cancel()
+	var errs []error
+	for _, s := range sessions {
+		select {
+		case <-s.done:
+			errs = append(errs, s.err)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// forget stops keeping track of the session once it has finished.
+func (r *Runner) forget(s *session) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	delete(r.sessions, s)
+	r.closeDoneIfFinished()
+}
+
+// Add starts the RunnerFunc as part of the Runner's current await, while it is
+// blocking, and executes its ShutdownFunc along with the rest during graceful
+// shutdown. This means runners can be started conditionally, or lazily, once
+// the await has begun:
+//
+//	go r.Await(adminRunner)
+//	... fetch the config ...
+//	if err := r.Add(workerRunner); err != nil {
+//		return err
+//	}
+//
+// A RunnerFunc which is added before the Runner's first await is started by
+// that await, after its own RunnerFuncs. If the Runner's shutdown has already
+// begun it returns ErrShuttingDown, and if the RunnerFunc panics it returns a
+// PanicError. If the Runner has several awaits running at once, the RunnerFunc
+// is added to the most recent of them.
+func (r *Runner) Add(runner RunnerFunc) error {
+	return r.add(component{start: runner.asStarter(), origin: runner})
+}
+
+// add queues the component until the Runner's first await, or adds it to the
+// current one.
+func (r *Runner) add(c component) error {
+	r.mux.Lock()
+	s := r.current
+	if s == nil {
+		r.pending = append(r.pending, c)
+		r.mux.Unlock()
+		return nil
+	}
+	r.mux.Unlock()
+	return s.add(c)
+}
@@ -0,0 +1,311 @@
+package rununtil_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRunner_Await(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	r := rununtil.New()
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(helperMakeFakeRunner(&hasBeenShutdown))
+	}()
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the shutdown function to have been called")
+	}
+}
+
+func TestRunner_CancelIsIsolated(t *testing.T) {
+	var hasBeenShutdown1, hasBeenShutdown2 atomic.Bool
+	r1 := rununtil.New()
+	r2 := rununtil.New()
+
+	errChan1 := make(chan error)
+	go func() {
+		errChan1 <- r1.Await(helperMakeFakeRunner(&hasBeenShutdown1))
+	}()
+	errChan2 := make(chan error)
+	go func() {
+		errChan2 <- r2.Await(helperMakeFakeRunner(&hasBeenShutdown2))
+	}()
+
+	helperKeepCancelling(t, r1.Cancel, errChan1)
+	if !hasBeenShutdown1.Load() {
+		t.Fatal("expected the first runner to have been shutdown")
+	}
+	select {
+	case <-errChan2:
+		t.Fatal("expected the second runner to still be running")
+	default:
+	}
+
+	helperKeepCancelling(t, r2.Cancel, errChan2)
+	if !hasBeenShutdown2.Load() {
+		t.Fatal("expected the second runner to have been shutdown")
+	}
+}
+
+func TestRunner_CancelAllIsIsolated(t *testing.T) {
+	var hasBeenShutdown, defaultShutdown atomic.Bool
+	r := rununtil.New()
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(helperMakeFakeRunner(&hasBeenShutdown))
+	}()
+	if !helperWaitFor(func() bool { return r.NumAwaiting() > 0 }) {
+		t.Fatal("expected the Runner to be awaiting")
+	}
+	done := make(chan struct{})
+	go func() {
+		rununtil.AwaitKillSignal(helperMakeFakeRunner(&defaultShutdown))
+		close(done)
+	}()
+	helperCancelUntilDone(t, done)
+	if !defaultShutdown.Load() {
+		t.Fatal("expected the package level await to have been shutdown")
+	}
+	rununtil.ForceNow()
+	select {
+	case <-errChan:
+		t.Fatal("expected CancelAll not to have stopped the Runner")
+	default:
+	}
+	if hasBeenShutdown.Load() || r.NumAwaiting() != 1 || r.ShuttingDown() {
+		t.Fatal("expected the Runner to still be running")
+	}
+
+	helperKeepCancelling(t, r.Cancel, errChan)
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the runner to have been shutdown")
+	}
+}
+
+func TestRunner_NilShutdownFunc(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	logger := &fakeLogger{}
+	r := rununtil.New(rununtil.WithLogger(logger))
+	forgetfulRunner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		return nil
+	})
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(helperMakeFakeRunner(&hasBeenShutdown), forgetfulRunner)
+	}()
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("expected a nil shutdown function to be a no-op, got: %v", err)
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the other shutdown function to have been called")
+	}
+	if !logger.contains("ERROR: runner 1 (rununtil_test.TestRunner_NilShutdownFunc.func1) returned a nil shutdown function") {
+		t.Fatalf("expected a warning to have been logged, got: %v", logger.lines)
+	}
+}
+
+func TestRunner_Add(t *testing.T) {
+	var hasBeenShutdown1, hasBeenShutdown2 atomic.Bool
+	started := make(chan struct{})
+	r := rununtil.New()
+	runner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		close(started)
+		return helperMakeFakeRunner(&hasBeenShutdown1)()
+	})
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(runner)
+	}()
+	<-started
+	if err := r.Add(helperMakeFakeRunner(&hasBeenShutdown2)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !hasBeenShutdown1.Load() || !hasBeenShutdown2.Load() {
+		t.Fatal("expected both shutdown functions to have been called")
+	}
+}
+
+func TestRunner_AddBeforeAwait(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	started := make(chan struct{})
+	r := rununtil.New()
+	if err := r.Add(rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		close(started)
+		return helperMakeFakeRunner(&hasBeenShutdown)()
+	})); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await()
+	}()
+	<-started
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the added runner to have been shutdown")
+	}
+}
+
+func TestRunner_AddDuringShutdown(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	r := rununtil.New()
+	addErr := make(chan error, 1)
+	runner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		return func() {
+			addErr <- r.Add(helperMakeFakeRunner(&hasBeenShutdown))
+		}
+	})
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(runner)
+	}()
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := <-addErr; !errors.Is(err, rununtil.ErrShuttingDown) {
+		t.Fatalf("expected ErrShuttingDown, got: %v", err)
+	}
+	if err := r.Add(helperMakeFakeRunner(&hasBeenShutdown)); !errors.Is(err, rununtil.ErrShuttingDown) {
+		t.Fatalf("expected ErrShuttingDown after the await has finished, got: %v", err)
+	}
+	if hasBeenShutdown.Load() {
+		t.Fatal("expected the rejected runner never to have been started")
+	}
+}
+
+func TestRunner_Stop(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	slowRunner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		return func() {
+			time.Sleep(10 * time.Millisecond)
+			hasBeenShutdown.Store(true)
+		}
+	})
+	r := rununtil.New()
+	r.AwaitInBackground(slowRunner)
+	r.AwaitInBackground(slowRunner)
+
+	if err := r.Stop(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected Stop to wait for the shutdown functions to complete")
+	}
+
+	// stopping a Runner which is not awaiting does nothing
+	if err := r.Stop(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRunner_StopErrors(t *testing.T) {
+	hang := make(chan struct{})
+	defer close(hang)
+	r := rununtil.New(rununtil.WithShutdownTimeout(10 * time.Millisecond))
+	r.AwaitInBackground(func() rununtil.ShutdownFunc {
+		return func() { <-hang }
+	})
+
+	if err := r.Stop(context.Background()); !errors.Is(err, rununtil.ErrShutdownTimeout) {
+		t.Fatalf("expected a shutdown timeout error, got: %v", err)
+	}
+}
+
+func TestRunner_StopContextDone(t *testing.T) {
+	hang := make(chan struct{})
+	defer close(hang)
+	r := rununtil.New()
+	r.AwaitInBackground(func() rununtil.ShutdownFunc {
+		return func() { <-hang }
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := r.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the context's error, got: %v", err)
+	}
+}
+
+func TestRunner_AddRacesWithCancel(t *testing.T) {
+	for iteration := 0; iteration < 20; iteration++ {
+		var added, shutdown atomic.Int32
+		r := rununtil.New()
+		runner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+			return func() { shutdown.Add(1) }
+		})
+
+		errChan := make(chan error)
+		go func() {
+			errChan <- r.Await()
+		}()
+		var wg sync.WaitGroup
+		for idx := 0; idx < 20; idx++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := r.Add(runner); err == nil {
+					added.Add(1)
+				}
+			}()
+		}
+		if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		wg.Wait()
+
+		if added.Load() != shutdown.Load() {
+			t.Fatalf("expected every runner that was added to be shut down, added %d and shut down %d", added.Load(), shutdown.Load())
+		}
+	}
+}
+
+func TestRunner_ShutdownOnlyOnce(t *testing.T) {
+	var calls atomic.Int32
+	runner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		closed := make(chan struct{})
+		return func() {
+			calls.Add(1)
+			// closing the channel a second time would panic
+			close(closed)
+		}
+	})
+	r := rununtil.New()
+	if err := r.Add(runner); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(runner)
+	}()
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.ShutdownAgain(); err != nil {
+		t.Fatalf("expected the second shutdown not to fail, got: %v", err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("expected each shutdown function to have been executed once, got %d calls", n)
+	}
+}
@@ -0,0 +1,18 @@
+package rununtil
+
+// AwaitRunners runs the runners until one of the kill signals, SIGINT or
+// SIGTERM unless configured otherwise with WithSignals, has been received, at
+// which point it executes the graceful shutdown functions as configured by the
+// options. It returns any errors that occurred during shutdown. It is the same
+// as AwaitKillSignalsWithOptions, but takes the runners as a slice, so that a
+// list of them which is built at runtime sits naturally alongside the options:
+//
+//	runners := []rununtil.RunnerFunc{httpRunner}
+//	if cfg.Metrics {
+//		runners = append(runners, metricsRunner)
+//	}
+//	err := rununtil.AwaitRunners(runners, rununtil.WithShutdownTimeout(10*time.Second))
+func AwaitRunners(runners []RunnerFunc, opts ...Option) error {
+	o := newOptions(opts)
+	return awaitKillSignals(o.signals, starters(runners), o)
+}
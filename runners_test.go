@@ -0,0 +1,38 @@
+package rununtil_test
+
+import (
+	"errors"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRununtilAwaitRunners(t *testing.T) {
+	var firstShutdown, secondShutdown atomic.Bool
+	signals := make(chan os.Signal, 1)
+	runners := []rununtil.RunnerFunc{
+		helperMakeFakeRunner(&firstShutdown),
+		helperMakeFakeRunner(&secondShutdown),
+	}
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- rununtil.AwaitRunners(runners, rununtil.WithSignalSource(signals), rununtil.WithSignals(syscall.SIGHUP))
+	}()
+	signals <- syscall.SIGHUP
+	if err := <-errChan; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !firstShutdown.Load() || !secondShutdown.Load() {
+		t.Fatal("expected every runner to have been shut down")
+	}
+}
+
+func TestRununtilAwaitRunners_Empty(t *testing.T) {
+	if err := rununtil.AwaitRunners(nil, rununtil.WithRequireRunners()); !errors.Is(err, rununtil.ErrNoRunners) {
+		t.Fatalf("expected ErrNoRunners, got: %v", err)
+	}
+}
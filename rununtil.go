@@ -6,6 +6,7 @@ The main usage of rununtil is to run your main app indefinitely until a SIGINT o
 The `AwaitKillSignal` is a blocking function which waits until a kill signal has been received.
 It takes in `RunnerFunc`s which are nonblocking functions which set off go routines (e.g. to run an HTTP server or a gRPC server) and return a `ShutdownFunc`.
 The `ShutdownFunc`s are executed when a kill signal has been received to allow for graceful shutdown of the go routines set off by the `RunnerFunc`s.
+They are executed one at a time, in the reverse order to which the `RunnerFunc`s were provided, each one completing before the next one begins.
 For example:
 	func Runner() rununtil.ShutdownFunc {
 		r := chi.NewRouter()
@@ -61,6 +62,11 @@ A nice pattern is to create a function that takes in the various depencies requi
 		rununtil.AwaitKillSignal(NewRunner(logger))
 	}
 
+Running an HTTP server like this is common enough that `HTTPServerRunner` does it for you, including ignoring the `http.ErrServerClosed` error:
+	rununtil.AwaitKillSignal(rununtil.HTTPServerRunner(httpServer, rununtil.WithDrainTimeout(10*time.Second)))
+
+On Windows, where there are no signals, Ctrl+C and Ctrl+Break are delivered as `os.Interrupt` (SIGINT), and closing the console window, logging off and shutting down are delivered as SIGTERM, so `AwaitKillSignal` triggers graceful shutdown for all of them.
+
 It is of course possible to specify which signals you would like to use to kill your application using the `AwaitKillSignals` function, for example:
 	rununtil.AwaitKillSignals([]os.Signal{syscall.SIGKILL, syscall.SIGHUP, syscall.SIGINT}, NewRunner(logger))
 
@@ -71,6 +77,27 @@ For testing purposes you may want to run your main function, which is using `run
 
 The `CancelAll` function results in the same behaviour as sending a real kill signal to your program would, i.e.~graceful shutdown is initiated.
 
+`CancelAll` cancels every await in the process that was started by the package level functions.
+If you need to run, and cancel, several independent awaits, then create a `Runner` for each of them:
+	r := rununtil.New()
+	go r.Await(NewRunner(logger))
+	... do your tests ...
+	r.Cancel()
+
+If your shutdown functions can fail, return a `ShutdownErrFunc` from an `ErrRunnerFunc` instead, and use `AwaitKillSignalE` (or `AwaitKillSignalsE`).
+The errors from all of the shutdown functions are combined and returned, so that you can choose an appropriate exit code:
+	if err := rununtil.AwaitKillSignalE(NewErrRunner(logger)); err != nil {
+		os.Exit(1)
+	}
+
+Runners that would rather watch for shutdown themselves can be written as `ContextRunnerFunc`s and run with `AwaitKillSignalCtx` (or `AwaitKillSignalsCtx`).
+They are all given the same context, which is cancelled as soon as a kill signal has been received and before any of the shutdown functions are executed.
+Its cause, from `context.Cause`, says why it was cancelled, either a `*SignalError` holding the kill signal or `ErrCancelled`, so that runners can choose how to clean up.
+
+To bound the total time that the shutdown takes, e.g. to fit within a Kubernetes pod's `terminationGracePeriodSeconds`, return a `CtxShutdownFunc` from a `DeadlineRunnerFunc` and use `AwaitKillSignalsDeadline`.
+All of the shutdown functions share one context, which can be passed straight into `http.Server.Shutdown`, and it returns once the deadline has passed even if some of them are still running.
+`HTTPServerDeadlineRunner` and `rununtilgrpc.GRPCServerDeadlineRunner` do this for HTTP and gRPC servers, respectively giving up draining the connections and escalating from `GracefulStop` to `Stop` once the deadline has passed.
+
 The old functions `KillSignal`, `Signals` and `Killed` are still here (for backwards compatibility), but they have been deprecated.
 Please use `AwaitKillSignal` instead of `KillSignal`, `AwaitKillSignals` instead of `Signals`, and `CancelAll` instead of `Killed` (now you can just run in a go routine main and then execute `CancelAll` to finish the `AwaitKillSignal`).
 */
@@ -78,32 +105,67 @@ package rununtil
 
 import (
 	"context"
-	"fmt"
 	"os"
-	"os/signal"
 	"sync"
-	"syscall"
-
-	"github.com/google/uuid"
-	"github.com/pkg/errors"
 )
 
+// cancellation is closed when an await is cancelled, once the reason it was
+// cancelled has been set.
+type cancellation struct {
+	done   chan struct{}
+	reason string
+}
+
+func newCancellation() *cancellation {
+	return &cancellation{done: make(chan struct{})}
+}
+
 type canceller struct {
-	signals map[string]chan struct{}
+	signals map[string]*cancellation
 	mux     sync.Mutex
 }
 
-func (canc *canceller) addChannel(key string, c chan struct{}) {
+func newCanceller() *canceller {
+	return &canceller{signals: make(map[string]*cancellation)}
+}
+
+func (canc *canceller) addChannel(key string, c *cancellation) {
 	canc.mux.Lock()
 	defer canc.mux.Unlock()
 	canc.signals[key] = c
 }
 
-func (canc *canceller) cancelAll() {
+// removeChannel forgets about the channel without closing it, so that an await
+// which has already finished is not cancelled again.
+func (canc *canceller) removeChannel(key string) {
+	canc.mux.Lock()
+	defer canc.mux.Unlock()
+	delete(canc.signals, key)
+}
+
+// cancel closes the channel with the key and forgets about it, so that
+// cancelling the same key again is a no-op.
+func (canc *canceller) cancel(key string, reason string) {
 	canc.mux.Lock()
 	defer canc.mux.Unlock()
-	for key := range canc.signals {
-		close(canc.signals[key])
+	if c, ok := canc.signals[key]; ok {
+		c.reason = reason
+		close(c.done)
+		delete(canc.signals, key)
+	}
+}
+
+// cancelAll closes every channel and forgets about them, so that calling it
+// again, or calling it when nothing is awaiting, is a no-op. Since addChannel
+// takes the same lock, every channel is either added before cancelAll, and
+// closed by it, or added afterwards, and left open: a channel can't be half
+// cancelled, however the calls race.
+func (canc *canceller) cancelAll(reason string) {
+	canc.mux.Lock()
+	defer canc.mux.Unlock()
+	for key, c := range canc.signals {
+		c.reason = reason
+		close(c.done)
 		delete(canc.signals, key)
 	}
 }
@@ -112,7 +174,7 @@ var globalCanceller canceller
 
 func init() {
 	globalCanceller.mux.Lock()
-	globalCanceller.signals = make(map[string]chan struct{})
+	globalCanceller.signals = make(map[string]*cancellation)
 	globalCanceller.mux.Unlock()
 }
 
@@ -124,45 +186,78 @@ type ShutdownFunc func()
 // returns a function which can shutdown those worker go routines.
 type RunnerFunc func() ShutdownFunc
 
+// ShutdownErrFunc is a variant of ShutdownFunc which returns an error if the
+// graceful shutdown did not succeed.
+type ShutdownErrFunc func() error
+
+// ErrRunnerFunc is a variant of RunnerFunc which returns a ShutdownErrFunc, so
+// that shutdown errors can be returned by AwaitKillSignalE and
+// AwaitKillSignalsE.
+type ErrRunnerFunc func() ShutdownErrFunc
+
 // AwaitKillSignal runs the provided RunnerFuncs until it receives a kill
 // signal, SIGINT or SIGTERM, at which point it executes the graceful shutdown
-// functions.
+// functions. On Windows this includes Ctrl+C, Ctrl+Break and closing the
+// console. With no RunnerFuncs it simply blocks until the kill signal is
+// received, see WithRequireRunners to make that an error instead.
 func AwaitKillSignal(runnerFuncs ...RunnerFunc) {
-	AwaitKillSignals([]os.Signal{syscall.SIGINT, syscall.SIGTERM}, runnerFuncs...)
+	AwaitKillSignals(defaultSignals(), runnerFuncs...)
 }
 
 // AwaitKillSignals runs the provided RunnerFuncs until the specified
 // signals have been recieved, at which point it executes the graceful shutdown
-// functions.
+// functions. If a RunnerFunc panics, the RunnerFuncs that have already started
+// are shut down and then it re-panics with a PanicError.
 func AwaitKillSignals(signals []os.Signal, runnerFuncs ...RunnerFunc) {
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, signals...)
+	AwaitUntil(context.Background(), signals, runnerFuncs...)
+}
 
-	finish := make(chan struct{})
-	uuid := uuid.New()
-	globalCanceller.addChannel(uuid.String(), finish)
+// AwaitKillSignalsReport is the same as AwaitKillSignals, except that it
+// returns the signal which triggered the shutdown, e.g. for logging or for
+// choosing an exit code. It returns nil if the shutdown was triggered by
+// CancelAll rather than a signal.
+func AwaitKillSignalsReport(signals []os.Signal, runnerFuncs ...RunnerFunc) os.Signal {
+	s := defaultRunner.newSession(signals, newOptions(nil))
+	repanic(s.run(starters(runnerFuncs)))
+	return s.received
+}
 
-	for _, runner := range runnerFuncs {
-		shutdown := runner()
-		defer shutdown()
-	}
+// AwaitKillSignalE is the same as AwaitKillSignal, except that it runs
+// ErrRunnerFuncs and returns the combined errors of all of their
+// ShutdownErrFuncs. For example:
+//	if err := rununtil.AwaitKillSignalE(runner); err != nil {
+//		os.Exit(1)
+//	}
+func AwaitKillSignalE(runnerFuncs ...ErrRunnerFunc) error {
+	return AwaitKillSignalsE(defaultSignals(), runnerFuncs...)
+}
 
-	// Wait for a kill signal
-	select {
-	case <-c:
-		break
-	case <-finish:
-		break
-	}
+// AwaitKillSignalsE is the same as AwaitKillSignals, except that it runs
+// ErrRunnerFuncs and returns the combined errors of all of their
+// ShutdownErrFuncs. Every ShutdownErrFunc is executed, even if an earlier one
+// has failed.
+func AwaitKillSignalsE(signals []os.Signal, runnerFuncs ...ErrRunnerFunc) error {
+	return awaitKillSignals(signals, starters(runnerFuncs), newOptions(nil))
 }
 
 // CancelAll will stop all the awaits in the same way that a kill
-// signal would stop them. To use:
+// signal would stop them. It does not stop the awaits of Runners created with
+// New. It only stops the awaits which have already started awaiting when it is
+// called; one which starts afterwards, even if concurrently, is unaffected and
+// has to be cancelled again. To use:
 //	go main()
 //	... do your tests ...
 //	rununtil.CancelAll()
 func CancelAll() {
-	globalCanceller.cancelAll()
+	CancelAllReason("")
+}
+
+// CancelAllReason is the same as CancelAll, except that the reason is logged by
+// each of the awaits, and is included in the cause of the context given to
+// their runners, so that it is clear which code path began the shutdown:
+//	rununtil.CancelAllReason("config reloaded")
+func CancelAllReason(reason string) {
+	defaultRunner.CancelReason(reason)
 }
 
 // KillSignal runs the provided runner function until it receives a kill signal,
@@ -196,16 +291,32 @@ func Killed(main func()) context.CancelFunc {
 	return cancel
 }
 
+// KilledContext is a variant of Killed for testing legacy code which bounds how
+// long the test waits. It runs the function provided and cancels it, with
+// CancelAll, once ctx is done, returning a channel which is closed once the
+// function has returned:
+//	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//	defer cancel()
+//	done := rununtil.KilledContext(ctx, main)
+//	... do some stuff, e.g. send some requests to the webserver ...
+//	cancel()
+//	<-done
+func KilledContext(ctx context.Context, main func()) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runMain(ctx, main)
+	}()
+	return done
+}
+
+// runMain runs main, cancelling it with CancelAll once ctx is done.
 func runMain(ctx context.Context, main func()) {
-	p, err := os.FindProcess(os.Getpid())
-	if err != nil {
-		fmt.Printf("ERROR: %+v\n", errors.Wrap(err, "trying to get PID"))
-	}
-	go killMainWhenDone(ctx, p)
+	go killMainWhenDone(ctx)
 	main()
 }
 
-func killMainWhenDone(ctx context.Context, p *os.Process) {
+func killMainWhenDone(ctx context.Context) {
 	<-ctx.Done()
 
 	CancelAll()
@@ -1,7 +1,11 @@
 package rununtil_test
 
 import (
+	"context"
+	"errors"
 	"os"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"testing"
 	"time"
@@ -9,23 +13,78 @@ import (
 	"github.com/kaluza-tech/rununtil"
 )
 
-func helperSendSignal(t *testing.T, p *os.Process, sent *bool, signal os.Signal, delay time.Duration) {
+// helperSendSignal sends the signal after the delay, once an await of the
+// package level functions is listening for it, since otherwise the signal
+// would kill the tests.
+func helperSendSignal(t *testing.T, p *os.Process, sent *atomic.Bool, signal os.Signal, delay time.Duration) {
+	helperSendSignalWhenAwaiting(t, p, sent, signal, delay, rununtil.NumAwaiting)
+}
+
+// helperSendSignalWhenAwaiting is the same as helperSendSignal, except that it
+// waits for numAwaiting to report that an await is listening.
+func helperSendSignalWhenAwaiting(t *testing.T, p *os.Process, sent *atomic.Bool, signal os.Signal, delay time.Duration, numAwaiting func() int) {
 	time.Sleep(delay)
+	if !helperWaitFor(func() bool { return numAwaiting() > 0 }) {
+		t.Errorf("expected an await to be listening for %v", signal)
+		return
+	}
 	if err := p.Signal(signal); err != nil {
 		t.Errorf("unexpected error occurred: %v", err)
 	}
-	*sent = true
+	sent.Store(true)
+}
+
+// helperWaitFor polls the condition until it holds, giving up after a second.
+// This keeps the tests from depending on exactly when the scheduler gets round
+// to running the go routines.
+func helperWaitFor(cond func() bool) bool {
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return true
+}
+
+// helperCancelUntilDone keeps cancelling the awaits with CancelAll until the
+// await under test reports that it is done, and returns what it reported.
+func helperCancelUntilDone[T any](t *testing.T, done <-chan T) T {
+	t.Helper()
+	return helperKeepCancelling(t, rununtil.CancelAll, done)
 }
 
-func helperMakeFakeRunner(hasBeenShutdown *bool) rununtil.RunnerFunc {
+// helperKeepCancelling keeps calling cancel until the await under test reports
+// that it is done, and returns what it reported. It keeps on cancelling
+// because cancelling only stops the awaits that have already started, and the
+// await under test may not have got that far yet.
+func helperKeepCancelling[T any](t *testing.T, cancel func(), done <-chan T) T {
+	t.Helper()
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.After(time.Second)
+	for {
+		cancel()
+		select {
+		case result := <-done:
+			return result
+		case <-ticker.C:
+		case <-timeout:
+			t.Fatal("expected the await to have finished")
+		}
+	}
+}
+
+func helperMakeFakeRunner(hasBeenShutdown *atomic.Bool) rununtil.RunnerFunc {
 	return rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
 		return rununtil.ShutdownFunc(func() {
-			*hasBeenShutdown = true
+			hasBeenShutdown.Store(true)
 		})
 	})
 }
 
-func helperMakeMain(hasBeenKilled *bool) func() {
+func helperMakeMain(hasBeenKilled *atomic.Bool) func() {
 	return func() {
 		rununtil.AwaitKillSignal(helperMakeFakeRunner(hasBeenKilled))
 	}
@@ -47,8 +106,8 @@ func TestRununtilAwaitKillSignal(t *testing.T) {
 	}
 	for _, test := range table {
 		t.Run(test.name, func(t *testing.T) {
-			var sentSignal bool
-			var hasBeenShutdown bool
+			var sentSignal atomic.Bool
+			var hasBeenShutdown atomic.Bool
 			p, err := os.FindProcess(os.Getpid())
 			if err != nil {
 				t.Fatalf("Unexpected error when finding process: %v", err)
@@ -56,10 +115,10 @@ func TestRununtilAwaitKillSignal(t *testing.T) {
 
 			go helperSendSignal(t, p, &sentSignal, test.signal, 1*time.Millisecond)
 			rununtil.AwaitKillSignal(helperMakeFakeRunner(&hasBeenShutdown))
-			if !sentSignal {
+			if !sentSignal.Load() {
 				t.Fatal("expected signal to have been sent")
 			}
-			if !hasBeenShutdown {
+			if !hasBeenShutdown.Load() {
 				t.Fatal("expected the shutdown function to have been called")
 			}
 		})
@@ -67,8 +126,8 @@ func TestRununtilAwaitKillSignal(t *testing.T) {
 }
 
 func TestRununtilAwaitKillSignal_MultipleRunnerFuncs(t *testing.T) {
-	var hasBeenShutdown1, hasBeenShutdown2, hasBeenShutdown3 bool
-	var sentSignal bool
+	var hasBeenShutdown1, hasBeenShutdown2, hasBeenShutdown3 atomic.Bool
+	var sentSignal atomic.Bool
 
 	p, err := os.FindProcess(os.Getpid())
 	if err != nil {
@@ -83,83 +142,396 @@ func TestRununtilAwaitKillSignal_MultipleRunnerFuncs(t *testing.T) {
 		helperMakeFakeRunner(&hasBeenShutdown3),
 	)
 
-	if !sentSignal {
+	if !sentSignal.Load() {
 		t.Fatal("expected signal to have been sent")
 	}
-	if !hasBeenShutdown1 {
+	if !hasBeenShutdown1.Load() {
 		t.Fatal("expected the shutdown function 1 to have been called")
 	}
-	if !hasBeenShutdown2 {
+	if !hasBeenShutdown2.Load() {
 		t.Fatal("expected the shutdown function 2 to have been called")
 	}
-	if !hasBeenShutdown3 {
+	if !hasBeenShutdown3.Load() {
 		t.Fatal("expected the shutdown function 3 to have been called")
 	}
 }
 
+func helperMakeFakeErrRunner(hasBeenShutdown *atomic.Bool, err error) rununtil.ErrRunnerFunc {
+	return rununtil.ErrRunnerFunc(func() rununtil.ShutdownErrFunc {
+		return rununtil.ShutdownErrFunc(func() error {
+			hasBeenShutdown.Store(true)
+			return err
+		})
+	})
+}
+
+func TestRununtilAwaitKillSignalE(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	var sentSignal atomic.Bool
+
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("Unexpected error when finding process: %v", err)
+	}
+
+	go helperSendSignal(t, p, &sentSignal, syscall.SIGINT, time.Millisecond)
+
+	if err := rununtil.AwaitKillSignalE(helperMakeFakeErrRunner(&hasBeenShutdown, nil)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !sentSignal.Load() {
+		t.Fatal("expected signal to have been sent")
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the shutdown function to have been called")
+	}
+}
+
+func TestRununtilAwaitKillSignalsE_CombinesErrors(t *testing.T) {
+	var hasBeenShutdown1, hasBeenShutdown2, hasBeenShutdown3 atomic.Bool
+	err1 := errors.New("error 1")
+	err3 := errors.New("error 3")
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- rununtil.AwaitKillSignalsE(
+			[]os.Signal{syscall.SIGINT},
+			helperMakeFakeErrRunner(&hasBeenShutdown1, err1),
+			helperMakeFakeErrRunner(&hasBeenShutdown2, nil),
+			helperMakeFakeErrRunner(&hasBeenShutdown3, err3),
+		)
+	}()
+	err := helperCancelUntilDone(t, errChan)
+	if !errors.Is(err, err1) {
+		t.Fatalf("expected error to contain %v, got: %v", err1, err)
+	}
+	if !errors.Is(err, err3) {
+		t.Fatalf("expected error to contain %v, got: %v", err3, err)
+	}
+	if !hasBeenShutdown1.Load() || !hasBeenShutdown2.Load() || !hasBeenShutdown3.Load() {
+		t.Fatal("expected all of the shutdown functions to have been called")
+	}
+}
+
+func TestRununtilAwaitKillSignalsReport(t *testing.T) {
+	table := []struct {
+		name   string
+		signal os.Signal
+	}{
+		{
+			name:   "Reports SIGINT",
+			signal: syscall.SIGINT,
+		},
+		{
+			name:   "Reports SIGTERM",
+			signal: syscall.SIGTERM,
+		},
+	}
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			var sentSignal atomic.Bool
+			var hasBeenShutdown atomic.Bool
+			p, err := os.FindProcess(os.Getpid())
+			if err != nil {
+				t.Fatalf("Unexpected error when finding process: %v", err)
+			}
+
+			go helperSendSignal(t, p, &sentSignal, test.signal, 1*time.Millisecond)
+			received := rununtil.AwaitKillSignalsReport(
+				[]os.Signal{syscall.SIGINT, syscall.SIGTERM},
+				helperMakeFakeRunner(&hasBeenShutdown),
+			)
+			if received != test.signal {
+				t.Fatalf("expected %v to have been reported, got: %v", test.signal, received)
+			}
+			if !hasBeenShutdown.Load() {
+				t.Fatal("expected the shutdown function to have been called")
+			}
+		})
+	}
+}
+
+func TestRununtilAwaitKillSignalsReport_CancelAll(t *testing.T) {
+	received := make(chan os.Signal)
+	go func() {
+		received <- rununtil.AwaitKillSignalsReport([]os.Signal{syscall.SIGINT})
+	}()
+	if sig := helperCancelUntilDone(t, received); sig != nil {
+		t.Fatalf("expected no signal to have been reported, got: %v", sig)
+	}
+}
+
 func TestRununtilKilled(t *testing.T) {
-	var hasBeenKilled bool
+	var hasBeenKilled atomic.Bool
 	cancel := rununtil.Killed(helperMakeMain(&hasBeenKilled))
+	// CancelAll only stops the awaits which have already started
+	if !helperWaitFor(func() bool { return rununtil.NumAwaiting() > 0 }) {
+		t.Fatal("expected main to have started awaiting")
+	}
 	cancel()
 
 	// yield control back to scheduler so that killing can actually happen
-	time.Sleep(time.Millisecond)
-	if !hasBeenKilled {
+	if !helperWaitFor(func() bool { return hasBeenKilled.Load() }) {
+		t.Fatal("expected main to have been killed")
+	}
+}
+
+func TestRununtilKilledContext(t *testing.T) {
+	var hasBeenKilled atomic.Bool
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := rununtil.KilledContext(ctx, helperMakeMain(&hasBeenKilled))
+	if !helperWaitFor(func() bool { return rununtil.NumAwaiting() > 0 }) {
+		t.Fatal("expected main to have started awaiting")
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected main to have returned once the context was done")
+	}
+	if !hasBeenKilled.Load() {
 		t.Fatal("expected main to have been killed")
 	}
 }
 
 func TestRununtilCancelAll(t *testing.T) {
-	var hasBeenKilled bool
+	var hasBeenKilled atomic.Bool
 	rununtil.Killed(helperMakeMain(&hasBeenKilled))
 
 	// yield control back to scheduler so that the go routines can actually
 	// start
-	time.Sleep(time.Millisecond)
+	if !helperWaitFor(func() bool { return rununtil.NumAwaiting() > 0 }) {
+		t.Fatal("expected main to have started awaiting")
+	}
 
 	rununtil.CancelAll()
 
 	// yield control back to scheduler so that killing can actually happen
-	time.Sleep(time.Millisecond)
-	if !hasBeenKilled {
+	if !helperWaitFor(func() bool { return hasBeenKilled.Load() }) {
 		t.Fatal("expected main to have been killed")
 	}
 }
 
 func TestRununtilCancelAll_MultipleTimes(t *testing.T) {
-	var hasBeenKilled bool
+	var hasBeenKilled atomic.Bool
 	for idx := 0; idx < 100; idx++ {
-		hasBeenKilled = false
+		hasBeenKilled.Store(false)
 		rununtil.Killed(helperMakeMain(&hasBeenKilled))
 
 		// yield control back to scheduler so that the go routines can actually
 		// start
-		time.Sleep(time.Millisecond)
+		if !helperWaitFor(func() bool { return rununtil.NumAwaiting() > 0 }) {
+			t.Fatal("expected main to have started awaiting")
+		}
 
 		rununtil.CancelAll()
 
 		// yield control back to scheduler so that killing can actually happen
-		time.Sleep(time.Millisecond)
-		if !hasBeenKilled {
+		if !helperWaitFor(func() bool { return hasBeenKilled.Load() }) {
 			t.Fatal("expected main to have been killed")
 		}
 	}
 }
 
 func TestRununtilCancelAll_Threadsafe(t *testing.T) {
-	var hasBeenKilledVec [100]bool
-	for idx := 0; idx < 100; idx++ {
-		cancel := rununtil.Killed(helperMakeMain(&hasBeenKilledVec[idx]))
-		cancel()
-		rununtil.CancelAll()
+	var hasBeenKilledVec [100]atomic.Bool
+	var cancels [100]context.CancelFunc
+	for idx := range cancels {
+		cancels[idx] = rununtil.Killed(helperMakeMain(&hasBeenKilledVec[idx]))
+	}
+	// CancelAll only stops the awaits which have already started
+	if !helperWaitFor(func() bool { return rununtil.NumAwaiting() >= len(cancels) }) {
+		t.Fatal("expected every main to have started awaiting")
+	}
+	var wg sync.WaitGroup
+	for _, cancel := range cancels {
+		wg.Add(2)
+		go func(cancel context.CancelFunc) {
+			defer wg.Done()
+			cancel()
+		}(cancel)
+		go func() {
+			defer wg.Done()
+			rununtil.CancelAll()
+		}()
 	}
+	wg.Wait()
 	// yield control back to scheduler so that killing can actually happen
-	time.Sleep(time.Millisecond)
-	for idx, hasBeenKilled := range hasBeenKilledVec {
-		if !hasBeenKilled {
+	for idx := range hasBeenKilledVec {
+		if !helperWaitFor(func() bool { return hasBeenKilledVec[idx].Load() }) {
 			t.Fatalf("expected main to have been killed: %d", idx)
 		}
 	}
+	// let the CancelAll of each Killed finish, so that it can't cancel the
+	// awaits of the tests which follow
+	time.Sleep(10 * time.Millisecond)
+}
+
+func TestRununtilCancelAll_Concurrently(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	done := make(chan struct{})
+	go func() {
+		rununtil.AwaitKillSignal(helperMakeFakeRunner(&hasBeenShutdown))
+		close(done)
+	}()
+	if !helperWaitFor(func() bool { return rununtil.NumAwaiting() > 0 }) {
+		t.Fatal("expected the await to have started")
+	}
+
+	var wg sync.WaitGroup
+	for idx := 0; idx < 10; idx++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for jdx := 0; jdx < 10; jdx++ {
+				rununtil.CancelAll()
+			}
+		}()
+	}
+	wg.Wait()
+
+	helperCancelUntilDone(t, done)
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the shutdown function to have been called")
+	}
+}
+
+func TestRununtilCancelAll_OnlyStartedAwaits(t *testing.T) {
+	rununtil.CancelAll()
+	done, cancel := rununtil.AwaitKillSignalAsync()
+	defer cancel()
+
+	select {
+	case <-done:
+		t.Fatal("expected an await started after CancelAll to still be running")
+	case <-time.After(10 * time.Millisecond):
+	}
+	rununtil.CancelAll()
+	<-done
+}
+
+func TestRununtilCancelAll_RacesWithAwaits(t *testing.T) {
+	stop := make(chan struct{})
+	cancelling := make(chan struct{})
+	go func() {
+		defer close(cancelling)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				rununtil.CancelAll()
+			}
+		}
+	}()
+	var dones []<-chan struct{}
+	for idx := 0; idx < 50; idx++ {
+		done, _ := rununtil.AwaitKillSignalAsync()
+		dones = append(dones, done)
+	}
+	close(stop)
+	<-cancelling
+
+	// each await was either cancelled or is still awaiting, and so is
+	// cancelled now
+	rununtil.CancelAll()
+	for idx, done := range dones {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatalf("expected await %d to have been cancelled", idx)
+		}
+	}
+}
+
+func TestRununtilCancelAll_Stress(t *testing.T) {
+	iterations := 20
+	if testing.Short() {
+		iterations = 2
+	}
+	for iteration := 0; iteration < iterations; iteration++ {
+		var started, shutdown, early atomic.Int32
+		runner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+			var hasStarted atomic.Bool
+			defer hasStarted.Store(true)
+			started.Add(1)
+			return func() {
+				if !hasStarted.Load() {
+					early.Add(1)
+				}
+				shutdown.Add(1)
+			}
+		})
+
+		var wg sync.WaitGroup
+		for idx := 0; idx < 50; idx++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				rununtil.AwaitKillSignals([]os.Signal{syscall.SIGINT}, runner, runner)
+			}()
+		}
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+		helperCancelUntilDone(t, done)
+
+		if started.Load() != 100 || shutdown.Load() != 100 {
+			t.Fatalf("expected every runner to be started and shut down once, got %d and %d", started.Load(), shutdown.Load())
+		}
+		if early.Load() != 0 {
+			t.Fatalf("expected no runner to be shut down before it had started, got: %d", early.Load())
+		}
+	}
+}
+
+func TestRununtilCancelAll_AfterShutdown(t *testing.T) {
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("Unexpected error when finding process: %v", err)
+	}
+	var sentSignal, hasBeenShutdown atomic.Bool
+	go helperSendSignal(t, p, &sentSignal, syscall.SIGINT, time.Millisecond)
+	rununtil.AwaitKillSignal(helperMakeFakeRunner(&hasBeenShutdown))
+
+	if n := rununtil.NumAwaiting(); n != 0 {
+		t.Fatalf("expected the finished await to no longer be cancellable, got %d awaiting", n)
+	}
+	rununtil.CancelAll()
+	rununtil.CancelAll()
+}
+
+func TestRununtilCancelAll_NothingAwaiting(t *testing.T) {
+	if !helperWaitFor(func() bool { return rununtil.NumAwaiting() == 0 }) {
+		t.Fatal("expected nothing to be awaiting")
+	}
+	rununtil.CancelAll()
+	rununtil.CancelAll()
+}
+
+func TestRununtilAwaitKillSignals_Repeatedly(t *testing.T) {
+	for idx := 0; idx < 50; idx++ {
+		done := make(chan struct{})
+		go func() {
+			rununtil.AwaitKillSignals([]os.Signal{syscall.SIGINT})
+			close(done)
+		}()
+		helperCancelUntilDone(t, done)
+	}
+
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("Unexpected error when finding process: %v", err)
+	}
+	var sentSignal atomic.Bool
+	go helperSendSignal(t, p, &sentSignal, syscall.SIGINT, time.Millisecond)
+	if received := rununtil.AwaitKillSignalsReport([]os.Signal{syscall.SIGINT}); received != syscall.SIGINT {
+		t.Fatalf("expected the latest await to have received SIGINT, got: %v", received)
+	}
 }
 
 // Annoyingly this test has to be run by itself to actually fail...
@@ -0,0 +1,17 @@
+module github.com/kaluza-tech/rununtil/rununtilgrpc
+
+go 1.20
+
+require (
+	github.com/kaluza-tech/rununtil v0.0.0-20261014070532-7d29ce3a0862
+	google.golang.org/grpc v1.64.1
+)
+
+require (
+	golang.org/x/net v0.26.0 // indirect
+	golang.org/x/sync v0.10.0 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+	golang.org/x/text v0.16.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240318140521-94a12d6c2237 // indirect
+	google.golang.org/protobuf v1.33.0 // indirect
+)
@@ -0,0 +1,113 @@
+// Package rununtilgrpc provides a rununtil.RunnerFunc for running a gRPC
+// server. It is a module of its own, so that using rununtil doesn't require
+// depending on gRPC.
+package rununtilgrpc
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+	"google.golang.org/grpc"
+)
+
+// Option configures the RunnerFunc created by GRPCServerRunner.
+type Option func(*options)
+
+type options struct {
+	stopTimeout  time.Duration
+	onServeError func(error)
+}
+
+// WithStopTimeout limits how long GracefulStop is given to finish the pending
+// RPCs during shutdown. Once the timeout has passed the server is stopped with
+// Stop, which cancels any RPCs that are still running. A timeout of zero, the
+// default, means wait for GracefulStop to finish.
+func WithStopTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.stopTimeout = timeout
+	}
+}
+
+// WithServeErrorHandler sets the function which is called if the server stops
+// serving because of an error. By default the error is only logged, with
+// rununtil.DefaultLogger, since the server can't tell which await is running
+// it. To shut down gracefully rather than carrying on without the server,
+// cancel the await which runs it:
+//
+//	r := rununtil.New()
+//	err := r.Await(rununtilgrpc.GRPCServerRunner(srv, lis, rununtilgrpc.WithServeErrorHandler(func(error) {
+//		r.Cancel()
+//	})))
+func WithServeErrorHandler(handler func(error)) Option {
+	return func(o *options) {
+		o.onServeError = handler
+	}
+}
+
+// GRPCServerRunner returns a rununtil.RunnerFunc which runs srv.Serve(lis) in a
+// go routine and whose ShutdownFunc calls srv.GracefulStop, falling back to
+// srv.Stop if WithStopTimeout has been given and it has run out.
+func GRPCServerRunner(srv *grpc.Server, lis net.Listener, opts ...Option) rununtil.RunnerFunc {
+	start := GRPCServerDeadlineRunner(srv, lis, opts...)
+	return func() rununtil.ShutdownFunc {
+		shutdown := start()
+		return func() {
+			shutdown(context.Background())
+		}
+	}
+}
+
+// GRPCServerDeadlineRunner is the same as GRPCServerRunner, except that it
+// returns a rununtil.DeadlineRunnerFunc for rununtil.AwaitKillSignalsDeadline.
+// Its CtxShutdownFunc falls back from srv.GracefulStop to srv.Stop once the
+// shutdown deadline context is done, or once WithStopTimeout has run out if
+// that comes first.
+func GRPCServerDeadlineRunner(srv *grpc.Server, lis net.Listener, opts ...Option) rununtil.DeadlineRunnerFunc {
+	o := options{onServeError: func(err error) {
+		rununtil.DefaultLogger().Errorf("grpc server on %s stopped serving: %v", lis.Addr(), err)
+	}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return func() rununtil.CtxShutdownFunc {
+		go func() {
+			if err := srv.Serve(lis); err != nil {
+				o.onServeError(err)
+			}
+		}()
+
+		return func(ctx context.Context) {
+			gracefulStop(ctx, srv, o.stopTimeout)
+		}
+	}
+}
+
+// gracefulStop gracefully stops the server, forcing it to stop if it hasn't
+// finished within the timeout or before the context is done.
+func gracefulStop(ctx context.Context, srv *grpc.Server, timeout time.Duration) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if ctx.Done() == nil {
+		srv.GracefulStop()
+		return
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		srv.Stop()
+		<-stopped
+	}
+}
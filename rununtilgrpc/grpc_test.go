@@ -0,0 +1,177 @@
+package rununtilgrpc_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+	"github.com/kaluza-tech/rununtil/rununtilgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// fakeLogger records the errors that are logged.
+type fakeLogger struct {
+	mux    sync.Mutex
+	errors []string
+}
+
+func (l *fakeLogger) Infof(format string, args ...interface{}) {}
+
+func (l *fakeLogger) Errorf(format string, args ...interface{}) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.errors = append(l.errors, fmt.Sprintf(format, args...))
+}
+
+func (l *fakeLogger) contains(substr string) bool {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	for _, line := range l.errors {
+		if strings.Contains(line, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+func helperStartServer(t *testing.T, opts ...rununtilgrpc.Option) (healthpb.HealthClient, func()) {
+	t.Helper()
+	client, shutdown := helperStartDeadlineServer(t, opts...)
+	return client, func() { shutdown(context.Background()) }
+}
+
+func helperStartDeadlineServer(t *testing.T, opts ...rununtilgrpc.Option) (healthpb.HealthClient, rununtil.CtxShutdownFunc) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	srv := grpc.NewServer()
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+
+	opts = append(opts, rununtilgrpc.WithServeErrorHandler(func(err error) {
+		t.Errorf("unexpected serve error: %v", err)
+	}))
+	shutdown := rununtilgrpc.GRPCServerDeadlineRunner(srv, lis, opts...)()
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return healthpb.NewHealthClient(conn), shutdown
+}
+
+func TestGRPCServerRunner(t *testing.T) {
+	client, shutdown := helperStartServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("expected the server to be serving, got: %v", err)
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("expected the server to be serving, got: %v", resp.Status)
+	}
+
+	shutdown()
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}); err == nil {
+		t.Fatal("expected the server to have been stopped")
+	}
+}
+
+// helperWatch opens a watch on the server, which is a stream that stays open
+// until it is cancelled, so it stops GracefulStop from ever finishing.
+func helperWatch(t *testing.T, client healthpb.HealthClient) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGRPCServerRunner_WithStopTimeout(t *testing.T) {
+	client, shutdown := helperStartServer(t, rununtilgrpc.WithStopTimeout(20*time.Millisecond))
+	helperWatch(t, client)
+
+	finished := make(chan struct{})
+	go func() {
+		shutdown()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("expected the server to have been stopped after the timeout")
+	}
+}
+
+func TestGRPCServerDeadlineRunner(t *testing.T) {
+	client, shutdown := helperStartDeadlineServer(t)
+	helperWatch(t, client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	finished := make(chan struct{})
+	go func() {
+		shutdown(ctx)
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("expected the server to have been stopped once the deadline had passed")
+	}
+}
+
+func TestGRPCServerDeadlineRunner_WithStopTimeout(t *testing.T) {
+	client, shutdown := helperStartDeadlineServer(t, rununtilgrpc.WithStopTimeout(20*time.Millisecond))
+	helperWatch(t, client)
+
+	finished := make(chan struct{})
+	go func() {
+		shutdown(context.Background())
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("expected the stop timeout to apply when it comes before the deadline")
+	}
+}
+
+func TestGRPCServerRunner_ServeErrorLoggedByDefault(t *testing.T) {
+	logger := &fakeLogger{}
+	rununtil.SetLogger(logger)
+	defer rununtil.SetLogger(nil)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// serving on a closed listener fails straight away
+	lis.Close()
+
+	shutdown := rununtilgrpc.GRPCServerRunner(grpc.NewServer(), lis)()
+	defer shutdown()
+	deadline := time.Now().Add(time.Second)
+	for !logger.contains("grpc server on " + lis.Addr().String() + " stopped serving") {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the serve error to have been logged, got: %v", logger.errors)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
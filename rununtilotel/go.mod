@@ -0,0 +1,18 @@
+module github.com/kaluza-tech/rununtil/rununtilotel
+
+go 1.20
+
+require (
+	github.com/kaluza-tech/rununtil v0.0.0-20261014070532-7d29ce3a0862
+	go.opentelemetry.io/otel v1.24.0
+	go.opentelemetry.io/otel/sdk v1.24.0
+	go.opentelemetry.io/otel/trace v1.24.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.1 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	go.opentelemetry.io/otel/metric v1.24.0 // indirect
+	golang.org/x/sync v0.10.0 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+)
@@ -0,0 +1,79 @@
+// Package rununtilotel traces rununtil's graceful shutdown with OpenTelemetry.
+// It is a module of its own, so that using rununtil doesn't require depending
+// on OpenTelemetry.
+package rununtilotel
+
+import (
+	"context"
+	"os"
+
+	"github.com/kaluza-tech/rununtil"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// ShutdownSpanName is the name of the span which covers the whole of the
+	// graceful shutdown.
+	ShutdownSpanName = "rununtil.shutdown"
+	// RunnerShutdownSpanName is the name of the child span which covers the
+	// shutdown function of a single runner.
+	RunnerShutdownSpanName = "rununtil.shutdown.runner"
+
+	// SignalKey is the attribute of the shutdown span holding the name of the
+	// kill signal that triggered the shutdown, or "none" if it was triggered
+	// some other way, e.g. by rununtil.CancelAll.
+	SignalKey = attribute.Key("rununtil.signal")
+	// RunnersKey is the attribute of the shutdown span holding the number of
+	// runners that were shut down.
+	RunnersKey = attribute.Key("rununtil.runners")
+	// RunnerIndexKey is the attribute of a runner shutdown span holding the
+	// index of the runner, in the order the runners were provided.
+	RunnerIndexKey = attribute.Key("rununtil.runner.index")
+)
+
+// WithTracer traces the graceful shutdown with the tracer. A span named
+// ShutdownSpanName is started as soon as shutdown begins, with a child span
+// named RunnerShutdownSpanName for each runner's shutdown function. Any
+// errors are recorded on the spans, which also set their status to error.
+func WithTracer(tracer trace.Tracer) rununtil.Option {
+	return rununtil.WithShutdownObserver(tracingObserver{tracer: tracer})
+}
+
+// tracingObserver is a rununtil.ShutdownObserver which starts a span for each
+// stage of the shutdown.
+type tracingObserver struct {
+	tracer trace.Tracer
+}
+
+func (o tracingObserver) StartShutdown(ctx context.Context, sig os.Signal, runners int) (context.Context, func(err error)) {
+	signal := "none"
+	if sig != nil {
+		signal = sig.String()
+	}
+	ctx, span := o.tracer.Start(ctx, ShutdownSpanName, trace.WithAttributes(
+		SignalKey.String(signal),
+		RunnersKey.Int(runners),
+	))
+	return ctx, endSpan(span)
+}
+
+func (o tracingObserver) StartRunnerShutdown(ctx context.Context, idx int) (context.Context, func(err error)) {
+	ctx, span := o.tracer.Start(ctx, RunnerShutdownSpanName, trace.WithAttributes(
+		RunnerIndexKey.Int(idx),
+	))
+	return ctx, endSpan(span)
+}
+
+// endSpan returns a function which records the error, if there is one, and
+// then ends the span.
+func endSpan(span trace.Span) func(err error) {
+	return func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
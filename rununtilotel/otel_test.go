@@ -0,0 +1,101 @@
+package rununtilotel_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+	"github.com/kaluza-tech/rununtil/rununtilotel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// helperAwaitAndCancel runs the runners with the Runner, and keeps on
+// cancelling it until it has finished awaiting.
+func helperAwaitAndCancel(t *testing.T, r *rununtil.Runner, runners ...rununtil.RunnerFunc) error {
+	t.Helper()
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(runners...)
+	}()
+	timeout := time.After(time.Second)
+	for {
+		r.Cancel()
+		select {
+		case err := <-errChan:
+			return err
+		case <-time.After(time.Millisecond):
+		case <-timeout:
+			t.Fatal("expected the await to have finished")
+		}
+	}
+}
+
+func helperAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestWithTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	r := rununtil.New(rununtilotel.WithTracer(provider.Tracer("test")))
+	runner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		return func() {}
+	})
+	panickingRunner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		return func() { panic("boom") }
+	})
+
+	err := helperAwaitAndCancel(t, r, runner, panickingRunner)
+	var panicErr *rununtil.PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("expected the panic to have been returned, got: %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got: %d", len(spans))
+	}
+	// the runners are shut down in reverse order, and each span ends before
+	// its parent does
+	panicked, succeeded, shutdown := spans[0], spans[1], spans[2]
+
+	if shutdown.Name() != rununtilotel.ShutdownSpanName {
+		t.Fatalf("expected the shutdown span to be called %q, got: %q", rununtilotel.ShutdownSpanName, shutdown.Name())
+	}
+	if signal := helperAttribute(shutdown, rununtilotel.SignalKey).AsString(); signal != "none" {
+		t.Fatalf("expected no signal to have been recorded, got: %q", signal)
+	}
+	if runners := helperAttribute(shutdown, rununtilotel.RunnersKey).AsInt64(); runners != 2 {
+		t.Fatalf("expected 2 runners to have been recorded, got: %d", runners)
+	}
+	if shutdown.Status().Code != codes.Error {
+		t.Fatalf("expected the shutdown span to have an error status, got: %v", shutdown.Status())
+	}
+
+	for idx, span := range []sdktrace.ReadOnlySpan{succeeded, panicked} {
+		if span.Name() != rununtilotel.RunnerShutdownSpanName {
+			t.Fatalf("expected the runner span to be called %q, got: %q", rununtilotel.RunnerShutdownSpanName, span.Name())
+		}
+		if span.Parent().SpanID() != shutdown.SpanContext().SpanID() {
+			t.Fatalf("expected the runner span %d to be a child of the shutdown span", idx)
+		}
+		if runner := helperAttribute(span, rununtilotel.RunnerIndexKey).AsInt64(); runner != int64(idx) {
+			t.Fatalf("expected the runner index %d to have been recorded, got: %d", idx, runner)
+		}
+	}
+	if succeeded.Status().Code == codes.Error {
+		t.Fatalf("expected the runner which shut down cleanly not to have an error status, got: %v", succeeded.Status())
+	}
+	if panicked.Status().Code != codes.Error || len(panicked.Events()) == 0 {
+		t.Fatal("expected the error of the runner which panicked to have been recorded")
+	}
+}
@@ -0,0 +1,81 @@
+package rununtiltest
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+// AssertNoLeaks is the same as RunAndShutdown, except that it also fails the
+// test if any of the go routines which the runner started are still running
+// once its ShutdownFunc has returned. This catches the common bug of a
+// ShutdownFunc which tells a worker to stop, but returns without waiting for
+// it to actually exit:
+//
+//	func TestNewWorker(t *testing.T) {
+//		rununtiltest.AssertNoLeaks(t, NewWorker(queue))
+//	}
+//
+// The go routines are given until the timeout to exit. Every go routine which
+// is started while the runner is being run counts, so the test mustn't be
+// run in parallel with any others.
+func AssertNoLeaks(t testing.TB, runner rununtil.RunnerFunc, opts ...Option) {
+	t.Helper()
+	o := newOptions(opts)
+	before := make(map[string]bool)
+	for _, g := range goroutines() {
+		before[g.id] = true
+	}
+
+	RunAndShutdown(t, runner, opts...)
+
+	deadline := time.Now().Add(o.timeout)
+	for {
+		var leaked []string
+		for _, g := range goroutines() {
+			if !before[g.id] {
+				leaked = append(leaked, g.stack)
+			}
+		}
+		if len(leaked) == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the runner not to have leaked any go routines, found %d:\n\n%s", len(leaked), strings.Join(leaked, "\n\n"))
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// goroutine is a go routine's id along with its stack trace.
+type goroutine struct {
+	id    string
+	stack string
+}
+
+// goroutines returns every go routine which is currently running.
+func goroutines() []goroutine {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	var all []goroutine
+	for _, stack := range bytes.Split(buf, []byte("\n\n")) {
+		// each stack begins with a line such as "goroutine 7 [chan receive]:"
+		fields := strings.Fields(string(stack))
+		if len(fields) < 2 || fields[0] != "goroutine" {
+			continue
+		}
+		all = append(all, goroutine{id: fields[1], stack: string(stack)})
+	}
+	return all
+}
@@ -0,0 +1,91 @@
+// Package rununtiltest provides helpers for testing rununtil runners, so that
+// the tests of every runner constructor don't each need their own go routines
+// and cancelling.
+package rununtiltest
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+// Option configures RunAndShutdown.
+type Option func(*options)
+
+type options struct {
+	startDelay time.Duration
+	timeout    time.Duration
+}
+
+func newOptions(opts []Option) options {
+	o := options{startDelay: 10 * time.Millisecond, timeout: time.Second}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithStartDelay sets how long the runner is left running, once it has
+// started, before it is shut down. The default is 10ms.
+func WithStartDelay(delay time.Duration) Option {
+	return func(o *options) {
+		o.startDelay = delay
+	}
+}
+
+// WithTimeout sets how long the runner is given to start, and then how long
+// its ShutdownFunc is given to return. The default is a second.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// RunAndShutdown runs the runner, waits for a moment once it has started and
+// then shuts it down, failing the test if the runner panics or if either it or
+// its ShutdownFunc doesn't return within the timeout:
+//
+//	func TestNewRunner(t *testing.T) {
+//		rununtiltest.RunAndShutdown(t, NewRunner(logger))
+//	}
+//
+// The runner is run by its own rununtil.Runner, which doesn't listen for any
+// signals, so the test doesn't affect, and isn't affected by, any of the
+// package level functions such as rununtil.CancelAll.
+func RunAndShutdown(t testing.TB, runner rununtil.RunnerFunc, opts ...Option) {
+	t.Helper()
+	o := newOptions(opts)
+
+	// a signal source which never delivers a signal stops the Runner from
+	// listening for them
+	r := rununtil.New(rununtil.WithSignalSource(make(chan os.Signal)), rununtil.WithShutdownTimeout(o.timeout))
+	started := make(chan struct{})
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- r.Await(func() rununtil.ShutdownFunc {
+			defer close(started)
+			return runner()
+		})
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(o.timeout):
+		t.Fatalf("expected the runner to have started within %v", o.timeout)
+	}
+	time.Sleep(o.startDelay)
+	r.Cancel()
+
+	// the Runner gives up on the ShutdownFunc after the timeout, so this only
+	// guards against the Runner itself getting stuck
+	select {
+	case err := <-errChan:
+		if err != nil {
+			t.Fatalf("expected the runner to have started and shut down cleanly, got: %v", err)
+		}
+	case <-time.After(2 * o.timeout):
+		t.Fatalf("expected the runner to have shut down within %v", o.timeout)
+	}
+}
@@ -0,0 +1,138 @@
+package rununtiltest_test
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+	"github.com/kaluza-tech/rununtil/rununtiltest"
+)
+
+// fakeT records whether the test would have failed, rather than failing the
+// real test.
+type fakeT struct {
+	testing.TB
+	failure string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Fatalf(format string, args ...interface{}) {
+	t.failure = fmt.Sprintf(format, args...)
+	runtime.Goexit()
+}
+
+// helperRunAndShutdown runs RunAndShutdown with a fakeT, returning the failure
+// that it reported, if any.
+func helperRunAndShutdown(t *testing.T, runner rununtil.RunnerFunc, opts ...rununtiltest.Option) string {
+	fake := &fakeT{TB: t}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		rununtiltest.RunAndShutdown(fake, runner, opts...)
+	}()
+	<-done
+	return fake.failure
+}
+
+func TestRunAndShutdown(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	runner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		return func() { hasBeenShutdown.Store(true) }
+	})
+
+	rununtiltest.RunAndShutdown(t, runner)
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the shutdown function to have been called")
+	}
+}
+
+func TestRunAndShutdown_Failures(t *testing.T) {
+	hang := make(chan struct{})
+	defer close(hang)
+	for _, tc := range []struct {
+		name     string
+		runner   rununtil.RunnerFunc
+		expected string
+	}{
+		{
+			name: "Start panics",
+			runner: func() rununtil.ShutdownFunc {
+				panic("boom")
+			},
+			expected: "runner panicked: boom",
+		},
+		{
+			name: "Shutdown panics",
+			runner: func() rununtil.ShutdownFunc {
+				return func() { panic("boom") }
+			},
+			expected: "runner panicked: boom",
+		},
+		{
+			name: "Shutdown hangs",
+			runner: func() rununtil.ShutdownFunc {
+				return func() { <-hang }
+			},
+			expected: "shutdown timed out",
+		},
+		{
+			name: "Start hangs",
+			runner: func() rununtil.ShutdownFunc {
+				<-hang
+				return func() {}
+			},
+			expected: "expected the runner to have started within",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			failure := helperRunAndShutdown(t, tc.runner, rununtiltest.WithTimeout(20*time.Millisecond))
+			if !strings.Contains(failure, tc.expected) {
+				t.Fatalf("expected the test to have failed with %q, got: %q", tc.expected, failure)
+			}
+		})
+	}
+}
+
+func TestAssertNoLeaks(t *testing.T) {
+	runner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		stop := make(chan struct{})
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			<-stop
+		}()
+		return func() {
+			close(stop)
+			<-stopped
+		}
+	})
+
+	rununtiltest.AssertNoLeaks(t, runner)
+}
+
+func TestAssertNoLeaks_Leaked(t *testing.T) {
+	leaked := make(chan struct{})
+	defer close(leaked)
+	runner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		go func() {
+			<-leaked
+		}()
+		return func() {}
+	})
+
+	fake := &fakeT{TB: t}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		rununtiltest.AssertNoLeaks(fake, runner, rununtiltest.WithTimeout(50*time.Millisecond))
+	}()
+	<-done
+	if !strings.Contains(fake.failure, "leaked any go routines, found 1") || !strings.Contains(fake.failure, "TestAssertNoLeaks_Leaked") {
+		t.Fatalf("expected the leaked go routine to have been reported, got: %q", fake.failure)
+	}
+}
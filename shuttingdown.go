@@ -0,0 +1,22 @@
+package rununtil
+
+// ShuttingDown reports whether the default Runner has begun shutting down,
+// see Runner.ShuttingDown.
+func ShuttingDown() bool {
+	return defaultRunner.ShuttingDown()
+}
+
+// ShuttingDown reports whether one of the Runner's awaits has begun shutting
+// down. It becomes true as soon as a kill signal has been received, or the
+// await has been cancelled, before the WithPreShutdown hooks are called and
+// any of the shutdown functions are executed, and stays true until the Runner
+// next awaits. It is cheap, and safe to call from many go routines at once, so
+// request handlers can use it to turn away new requests:
+//
+//	if r.ShuttingDown() {
+//		w.WriteHeader(http.StatusServiceUnavailable)
+//		return
+//	}
+func (r *Runner) ShuttingDown() bool {
+	return r.shuttingDown.Load()
+}
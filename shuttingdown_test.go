@@ -0,0 +1,64 @@
+package rununtil_test
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRunner_ShuttingDown(t *testing.T) {
+	var atPreShutdown atomic.Bool
+	var r *rununtil.Runner
+	r = rununtil.New(rununtil.WithPreShutdown(func() { atPreShutdown.Store(r.ShuttingDown()) }))
+	var atStart, atShutdown atomic.Bool
+	runner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		atStart.Store(r.ShuttingDown())
+		return func() { atShutdown.Store(r.ShuttingDown()) }
+	})
+
+	for i := 0; i < 2; i++ {
+		errChan := make(chan error)
+		go func() {
+			errChan <- r.Await(runner)
+		}()
+		if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if atStart.Load() {
+			t.Fatal("expected not to be shutting down once the await had begun")
+		}
+		if !atPreShutdown.Load() {
+			t.Fatal("expected to be shutting down before the pre-shutdown hooks were called")
+		}
+		if !atShutdown.Load() {
+			t.Fatal("expected to be shutting down while the shutdown functions were executed")
+		}
+		if !r.ShuttingDown() {
+			t.Fatal("expected to still be shutting down after the await had finished")
+		}
+	}
+}
+
+func TestRununtilShuttingDown(t *testing.T) {
+	var atStart, atShutdown atomic.Bool
+	runner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		atStart.Store(rununtil.ShuttingDown())
+		return func() { atShutdown.Store(rununtil.ShuttingDown()) }
+	})
+
+	done := make(chan struct{})
+	go func() {
+		rununtil.AwaitKillSignal(runner)
+		close(done)
+	}()
+	helperKeepCancelling(t, rununtil.CancelAll, done)
+
+	if atStart.Load() {
+		t.Fatal("expected not to be shutting down once the await had begun")
+	}
+	if !atShutdown.Load() {
+		t.Fatal("expected to be shutting down while the shutdown functions were executed")
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !windows
+
+package rununtil
+
+import (
+	"os"
+	"syscall"
+)
+
+// defaultSignals returns the kill signals that are awaited when none are
+// specified, SIGINT and SIGTERM.
+func defaultSignals() []os.Signal {
+	return []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+}
+
+// fatalSignals returns the signals which kill the process by default, but
+// which can be caught, for WithCatchAllFatalSignals.
+func fatalSignals() []os.Signal {
+	return []os.Signal{syscall.SIGHUP, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGTERM}
+}
@@ -0,0 +1,28 @@
+package rununtil
+
+import (
+	"os"
+	"syscall"
+)
+
+// defaultSignals returns the kill signals that are awaited when none are
+// specified. Windows doesn't have signals, but os/signal translates its
+// console control events into them:
+//
+//	CTRL_C_EVENT and CTRL_BREAK_EVENT become os.Interrupt (SIGINT)
+//	CTRL_CLOSE_EVENT, CTRL_LOGOFF_EVENT and CTRL_SHUTDOWN_EVENT become SIGTERM
+//
+// So closing the console window, logging off or shutting down the machine
+// triggers graceful shutdown, as well as Ctrl+C and Ctrl+Break. The process
+// is only given a few seconds to exit after the last three, so keep the
+// shutdown short.
+func defaultSignals() []os.Signal {
+	return []os.Signal{os.Interrupt, syscall.SIGTERM}
+}
+
+// fatalSignals returns the signals which kill the process by default, but
+// which can be caught, for WithCatchAllFatalSignals. The console control
+// events are only ever translated into these two.
+func fatalSignals() []os.Signal {
+	return []os.Signal{os.Interrupt, syscall.SIGTERM}
+}
@@ -0,0 +1,52 @@
+package rununtil
+
+import (
+	"os"
+	"os/signal"
+)
+
+// signalNotify and signalStop are used to start and stop listening for the
+// signals, and signalIgnore to ignore them, so that the tests can check which
+// signals are listened for without touching the process' real signal handling.
+var (
+	signalNotify = signal.Notify
+	signalStop   = signal.Stop
+	signalIgnore = signal.Ignore
+)
+
+// WithSignalSource makes the await receive its signals from the channel rather
+// than from the operating system, so that tests can deterministically trigger
+// a kill signal, or any other signal, without sending a real one to the
+// process:
+//
+//	signals := make(chan os.Signal)
+//	r := rununtil.New(rununtil.WithSignalSource(signals))
+//	go r.Await(runner)
+//	signals <- syscall.SIGTERM
+//
+// The signals are handled exactly as if they had come from the operating
+// system, and os/signal is not used at all.
+func WithSignalSource(signals <-chan os.Signal) Option {
+	return func(o *options) {
+		o.signalSource = signals
+	}
+}
+
+// WithSignalBuffer sets how many signals can be waiting to be handled, where
+// the default is one. The operating system's signals are delivered with
+// os/signal, which drops a signal rather than block if the buffer is full, so
+// a bigger buffer keeps signals which arrive in a burst, e.g. a second Ctrl+C
+// pressed while the first is still being handled.
+//
+// The signals are read until the shutdown has finished: while the runners are
+// running, during the lame duck delay, when a second kill signal hurries the
+// shutdown, and while the shutdown functions run, when a second kill signal
+// is logged and ignored, or with WithForceQuitOnSecondSignal exits the
+// process. Either way a repeat within the WithSignalCoalescing window is
+// dropped. The buffer has no effect with WithSignalSource, whose channel is
+// read directly.
+func WithSignalBuffer(n int) Option {
+	return func(o *options) {
+		o.signalBuffer = n
+	}
+}
@@ -0,0 +1,109 @@
+package rununtil_test
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRununtilWithSignalSource(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	signals := make(chan os.Signal)
+	r := rununtil.New(rununtil.WithSignalSource(signals))
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(helperMakeFakeRunner(&hasBeenShutdown))
+	}()
+	// SIGTERM is one of the default kill signals, and would kill the tests if
+	// it were actually sent
+	signals <- syscall.SIGTERM
+	if err := <-errChan; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the shutdown function to have been called")
+	}
+}
+
+func TestRununtilWithSignalSource_Closed(t *testing.T) {
+	signals := make(chan os.Signal)
+	r := rununtil.New(rununtil.WithSignalSource(signals))
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await()
+	}()
+	close(signals)
+	select {
+	case <-errChan:
+		t.Fatal("expected closing the signal source not to stop the await")
+	default:
+	}
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRununtilSignalNotify(t *testing.T) {
+	var mux sync.Mutex
+	var notified []os.Signal
+	defer rununtil.SetSignalNotify(func(c chan<- os.Signal, sig ...os.Signal) {
+		mux.Lock()
+		defer mux.Unlock()
+		notified = append(notified, sig...)
+	})()
+
+	done := make(chan struct{})
+	go func() {
+		rununtil.AwaitKillSignalsWithReload([]os.Signal{syscall.SIGINT}, []os.Signal{syscall.SIGHUP}, func() {})
+		close(done)
+	}()
+	helperCancelUntilDone(t, done)
+
+	mux.Lock()
+	defer mux.Unlock()
+	if len(notified) != 2 || notified[0] != syscall.SIGINT || notified[1] != syscall.SIGHUP {
+		t.Fatalf("expected to have listened for SIGINT and SIGHUP, got: %v", notified)
+	}
+}
+
+func TestRununtilWithSignalBuffer(t *testing.T) {
+	notified := make(chan chan<- os.Signal, 1)
+	defer rununtil.SetSignalNotify(func(c chan<- os.Signal, sig ...os.Signal) {
+		notified <- c
+	})()
+	clock := newFakeClock()
+	r := rununtil.New(rununtil.WithClock(clock), rununtil.WithLameDuckDelay(time.Hour), rununtil.WithSignalBuffer(2))
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await()
+	}()
+	c := <-notified
+	if cap(c) != 2 {
+		t.Fatalf("expected a buffer of 2, got: %d", cap(c))
+	}
+	// both signals arrive before either has been handled, and the second
+	// hurries the lame duck delay begun by the first
+	for i := 0; i < 2; i++ {
+		select {
+		case c <- syscall.SIGTERM:
+		default:
+			t.Fatal("expected the signal to have been buffered")
+		}
+	}
+	select {
+	case err := <-errChan:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the second signal to have skipped the lame duck delay")
+	}
+}
@@ -0,0 +1,112 @@
+package rununtil
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// DeferStack collects the cleanups of the resources that a StackRunnerFunc
+// has acquired. It is like defer, except that the cleanups are executed during
+// graceful shutdown rather than when the StackRunnerFunc returns, or straight
+// away if it fails part way through setting up, so only what was actually
+// acquired is ever cleaned up.
+type DeferStack struct {
+	mux      sync.Mutex
+	cleanups []func() error
+}
+
+// Defer pushes a cleanup onto the stack.
+func (d *DeferStack) Defer(cleanup func()) {
+	d.DeferErr(func() error {
+		cleanup()
+		return nil
+	})
+}
+
+// DeferErr pushes a cleanup which can fail, such as a Close method, onto the
+// stack.
+func (d *DeferStack) DeferErr(cleanup func() error) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	d.cleanups = append(d.cleanups, cleanup)
+}
+
+// unwind executes the cleanups in the reverse order that they were pushed,
+// and returns their combined errors. If one of them panics the rest are still
+// executed, and then it re-panics with a PanicError for the first panic.
+func (d *DeferStack) unwind() error {
+	d.mux.Lock()
+	cleanups := d.cleanups
+	d.cleanups = nil
+	d.mux.Unlock()
+
+	var errs []error
+	var first *PanicError
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanup := cleanups[i]
+		panicErr := callSafely(func() {
+			errs = append(errs, cleanup())
+		})
+		if panicErr != nil && first == nil {
+			first = panicErr
+		}
+	}
+	if first != nil {
+		panic(first)
+	}
+	return errors.Join(errs...)
+}
+
+// StackRunnerFunc is a variant of RunnerFunc which, rather than returning a
+// ShutdownFunc, pushes the cleanup of each resource onto the DeferStack as
+// soon as it has acquired it, and returns an error if it fails to set up:
+//
+//	func(stack *rununtil.DeferStack) error {
+//		db, err := sql.Open("postgres", dsn)
+//		if err != nil {
+//			return err
+//		}
+//		stack.DeferErr(db.Close)
+//		lis, err := net.Listen("tcp", addr)
+//		if err != nil {
+//			// only the db is closed
+//			return err
+//		}
+//		stack.DeferErr(lis.Close)
+//		go serve(lis, db)
+//		return nil
+//	}
+type StackRunnerFunc func(stack *DeferStack) error
+
+// asStarter converts the StackRunnerFunc into a starter which unwinds the
+// stack when it is shut down, or as soon as the StackRunnerFunc fails or
+// panics.
+func (runner StackRunnerFunc) asStarter() starter {
+	return func(context.Context) (stop stopFunc, err error) {
+		stack := &DeferStack{}
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				_ = stack.unwind()
+				panic(recovered)
+			}
+		}()
+		if err := runner(stack); err != nil {
+			return nil, errors.Join(err, stack.unwind())
+		}
+		return func(context.Context) error {
+			return stack.unwind()
+		}, nil
+	}
+}
+
+// AwaitKillSignalsStack starts each of the StackRunnerFuncs in turn, and then
+// runs them until a kill signal, SIGINT or SIGTERM, has been received, at
+// which point it unwinds each of their DeferStacks. If one of them fails to
+// set up then its stack is unwound straight away, the StackRunnerFuncs which
+// have already started are shut down, and it returns the error, or a
+// PanicError if it panicked. Otherwise it returns any errors that the cleanups
+// returned.
+func AwaitKillSignalsStack(runnerFuncs ...StackRunnerFunc) error {
+	return awaitKillSignals(defaultSignals(), starters(runnerFuncs), newOptions(nil))
+}
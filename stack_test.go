@@ -0,0 +1,87 @@
+package rununtil_test
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+// cleanupRecorder records the order in which the resources are cleaned up.
+type cleanupRecorder struct {
+	mux     sync.Mutex
+	cleaned []string
+}
+
+func (rec *cleanupRecorder) cleanup(name string) func() {
+	return func() {
+		rec.mux.Lock()
+		defer rec.mux.Unlock()
+		rec.cleaned = append(rec.cleaned, name)
+	}
+}
+
+func TestRununtilAwaitKillSignalsStack(t *testing.T) {
+	rec := &cleanupRecorder{}
+	closeErr := errors.New("close failed")
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- rununtil.AwaitKillSignalsStack(func(stack *rununtil.DeferStack) error {
+			stack.Defer(rec.cleanup("db"))
+			stack.DeferErr(func() error {
+				rec.cleanup("listener")()
+				return closeErr
+			})
+			return nil
+		})
+	}()
+	if err := helperCancelUntilDone(t, errChan); !errors.Is(err, closeErr) {
+		t.Fatalf("expected the cleanup's error, got: %v", err)
+	}
+	if expected := []string{"listener", "db"}; !reflect.DeepEqual(rec.cleaned, expected) {
+		t.Fatalf("expected the stack to be unwound in reverse, %v, got: %v", expected, rec.cleaned)
+	}
+}
+
+func TestRununtilAwaitKillSignalsStack_SetupFails(t *testing.T) {
+	rec := &cleanupRecorder{}
+	bindErr := errors.New("address already in use")
+
+	err := rununtil.AwaitKillSignalsStack(
+		func(stack *rununtil.DeferStack) error {
+			stack.Defer(rec.cleanup("first runner"))
+			return nil
+		},
+		func(stack *rununtil.DeferStack) error {
+			stack.Defer(rec.cleanup("db"))
+			stack.Defer(rec.cleanup("cache"))
+			return bindErr
+		},
+	)
+	if !errors.Is(err, bindErr) {
+		t.Fatalf("expected the setup error, got: %v", err)
+	}
+	// only what was acquired is cleaned up, straight away, before the runner
+	// which had already started is shut down
+	if expected := []string{"cache", "db", "first runner"}; !reflect.DeepEqual(rec.cleaned, expected) {
+		t.Fatalf("expected %v to have been cleaned up, got: %v", expected, rec.cleaned)
+	}
+}
+
+func TestRununtilAwaitKillSignalsStack_SetupPanics(t *testing.T) {
+	rec := &cleanupRecorder{}
+	err := rununtil.AwaitKillSignalsStack(func(stack *rununtil.DeferStack) error {
+		stack.Defer(rec.cleanup("db"))
+		panic("boom")
+	})
+	var panicErr *rununtil.PanicError
+	if !errors.As(err, &panicErr) || panicErr.Value != "boom" {
+		t.Fatalf("expected a PanicError, got: %v", err)
+	}
+	if expected := []string{"db"}; !reflect.DeepEqual(rec.cleaned, expected) {
+		t.Fatalf("expected %v to have been cleaned up, got: %v", expected, rec.cleaned)
+	}
+}
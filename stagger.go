@@ -0,0 +1,48 @@
+package rununtil
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// WithShutdownStagger waits for the interval between each of the shutdown
+// functions when they are executed sequentially, so that runners which all
+// call into the same dependency during shutdown, e.g. to deregister from
+// service discovery, don't all do so at once. It has no effect with
+// OrderParallel. An interval of zero, the default, executes each shutdown
+// function as soon as the previous one has completed.
+func WithShutdownStagger(interval time.Duration) Option {
+	return func(o *options) {
+		o.staggerInterval = interval
+	}
+}
+
+// WithShutdownJitter adds a random delay of up to jitter to each of the
+// WithShutdownStagger intervals, so that many processes which are shut down
+// at the same time, e.g. by a rolling deploy, don't stay in step with one
+// another. It has no effect without WithShutdownStagger.
+func WithShutdownJitter(jitter time.Duration) Option {
+	return func(o *options) {
+		o.staggerJitter = jitter
+	}
+}
+
+// stagger waits for the stagger interval, plus its jitter, before the next
+// shutdown function is executed. It stops waiting if the context is done, so
+// that the stagger never holds up the shutdown past its deadline, or if the
+// shutdown has been hurried by ForceNow.
+func stagger(ctx context.Context, opts options) {
+	if opts.staggerInterval <= 0 {
+		return
+	}
+	delay := opts.staggerInterval
+	if opts.staggerJitter > 0 {
+		delay += time.Duration(rand.Int63n(int64(opts.staggerJitter)))
+	}
+	select {
+	case <-opts.clock.After(delay):
+	case <-ctx.Done():
+	case <-opts.hurry:
+	}
+}
@@ -0,0 +1,101 @@
+package rununtil_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func helperMakeStaggeredRunners(shutdowns chan<- int, n int) []rununtil.RunnerFunc {
+	runners := make([]rununtil.RunnerFunc, n)
+	for i := range runners {
+		i := i
+		runners[i] = rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+			return func() { shutdowns <- i }
+		})
+	}
+	return runners
+}
+
+func TestRununtilWithShutdownStagger(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		opts    []rununtil.Option
+		advance time.Duration
+	}{
+		{"Interval", []rununtil.Option{rununtil.WithShutdownStagger(time.Minute)}, time.Minute},
+		{"Jitter", []rununtil.Option{rununtil.WithShutdownStagger(time.Minute), rununtil.WithShutdownJitter(time.Hour)}, time.Minute + time.Hour},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			clock := newFakeClock()
+			shutdowns := make(chan int, 3)
+			r := rununtil.New(append(tc.opts, rununtil.WithClock(clock))...)
+
+			errChan := make(chan error)
+			go func() {
+				errChan <- r.Await(helperMakeStaggeredRunners(shutdowns, 3)...)
+			}()
+			helperKeepCancelling(t, r.Cancel, clock.waiting)
+
+			for _, expected := range []int{2, 1} {
+				if idx := <-shutdowns; idx != expected {
+					t.Fatalf("expected runner %d to be shut down, got: %d", expected, idx)
+				}
+				select {
+				case idx := <-shutdowns:
+					t.Fatalf("expected runner %d to wait for the stagger, but it was shut down", idx)
+				default:
+				}
+				clock.advance(tc.advance)
+				if expected == 2 {
+					<-clock.waiting
+				}
+			}
+			if err := <-errChan; err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if idx := <-shutdowns; idx != 0 {
+				t.Fatalf("expected runner 0 to be shut down, got: %d", idx)
+			}
+		})
+	}
+}
+
+func TestRununtilWithShutdownStagger_Deadline(t *testing.T) {
+	shutdowns := make(chan int, 2)
+	r := rununtil.New(rununtil.WithShutdownStagger(time.Hour), rununtil.WithShutdownDeadline(20*time.Millisecond))
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(helperMakeStaggeredRunners(shutdowns, 2)...)
+	}()
+	if err := helperKeepCancelling(t, r.Cancel, errChan); !errors.Is(err, rununtil.ErrShutdownDeadline) {
+		t.Fatalf("expected a shutdown deadline error, got: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-shutdowns:
+		case <-time.After(time.Second):
+			t.Fatal("expected the deadline to cut the stagger short")
+		}
+	}
+}
+
+func TestRununtilWithShutdownStagger_Parallel(t *testing.T) {
+	shutdowns := make(chan int, 2)
+	r := rununtil.New(rununtil.WithShutdownStagger(time.Hour), rununtil.WithShutdownOrder(rununtil.OrderParallel))
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(helperMakeStaggeredRunners(shutdowns, 2)...)
+	}()
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(shutdowns) != 2 {
+		t.Fatalf("expected the stagger to have no effect on a parallel shutdown, got %d shutdowns", len(shutdowns))
+	}
+}
@@ -0,0 +1,61 @@
+package rununtil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrStartupTimeout is returned when a runner did not return its shutdown
+// function within the WithStartupTimeout timeout.
+var ErrStartupTimeout = errors.New("startup timeout exceeded")
+
+// WithStartupTimeout limits how long each runner is given to return its
+// shutdown function, so that a runner whose synchronous setup blocks, e.g. on a
+// slow net.Listen or database connection, can't hang the process in startup
+// with no way of interrupting it. If a runner hasn't returned within the
+// timeout, the runners which have already started are shut down and the await
+// returns an error wrapping ErrStartupTimeout. Should the runner return after
+// all, its shutdown function is executed straight away. A timeout of zero, the
+// default, waits for as long as the runners take to start.
+func WithStartupTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.startupTimeout = timeout
+	}
+}
+
+// startWithin runs the component's starter, giving up on it if it hasn't
+// returned within the startup timeout.
+func (s *session) startWithin(idx int, c component) (stopFunc, error) {
+	timeout := s.opts.startupTimeout
+	if timeout <= 0 {
+		return startSafely(s.ctx, c.start)
+	}
+
+	type started struct {
+		stop stopFunc
+		err  error
+	}
+	done := make(chan started, 1)
+	expired := s.opts.clock.After(timeout)
+	go func() {
+		stop, err := startSafely(s.ctx, c.start)
+		done <- started{stop, err}
+	}()
+	select {
+	case res := <-done:
+		return res.stop, res.err
+	case <-expired:
+		go func() {
+			// nothing else will shut the runner down once it has been
+			// given up on
+			res := <-done
+			if res.err == nil && res.stop != nil {
+				s.opts.logger.Errorf("%s started after its startup timeout, shutting it down", c.describe(idx))
+				_ = shutdownSafely(context.Background(), res.stop)
+			}
+		}()
+		return nil, fmt.Errorf("%w: %s did not start within %v", ErrStartupTimeout, c.describe(idx), timeout)
+	}
+}
@@ -0,0 +1,60 @@
+package rununtil_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRununtilWithStartupTimeout(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	release := make(chan struct{})
+	lateShutdown := make(chan struct{})
+	blockingRunner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		<-release
+		return func() { close(lateShutdown) }
+	})
+	r := rununtil.New(rununtil.WithStartupTimeout(20 * time.Millisecond))
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(helperMakeFakeRunner(&hasBeenShutdown), blockingRunner)
+	}()
+	select {
+	case err := <-errChan:
+		if !errors.Is(err, rununtil.ErrStartupTimeout) {
+			t.Fatalf("expected a startup timeout error, got: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the await to have given up on the blocking runner")
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the runner which had started to have been shut down")
+	}
+
+	close(release)
+	select {
+	case <-lateShutdown:
+	case <-time.After(time.Second):
+		t.Fatal("expected the runner which started late to have been shut down")
+	}
+}
+
+func TestRununtilWithStartupTimeout_InTime(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	r := rununtil.New(rununtil.WithStartupTimeout(time.Second))
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(helperMakeFakeRunner(&hasBeenShutdown))
+	}()
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the shutdown function to have been called")
+	}
+}
@@ -0,0 +1,116 @@
+package rununtil
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// RunnerStatus is the state of one of a Runner's runners, as returned by
+// Runner.Status.
+type RunnerStatus struct {
+	// Index is the index of the runner, as used in the errors and logs of
+	// its await.
+	Index int
+	// Name is the name of the runner, if it was added with Runner.AddNamed.
+	Name string
+	// StartedAt is when the runner started, or zero if it hasn't yet, e.g.
+	// because it was added before the Runner's first await.
+	StartedAt time.Time
+	// ShuttingDown is set once the runner's shutdown function has begun.
+	ShuttingDown bool
+	// ShutDown is set once the runner's shutdown function has completed, or
+	// the await has stopped waiting for it.
+	ShutDown bool
+	// ShutdownDuration is how long the runner's shutdown took, once it has
+	// shut down.
+	ShutdownDuration time.Duration
+}
+
+// Status returns the state of each of the runners of the Runner's most recent
+// await, in the order of their indexes, or of the runners which have been
+// added for its first await if it hasn't begun yet. It is safe to call from
+// any go routine, so it can back a debug endpoint:
+//
+//	http.HandleFunc("/debug/rununtil", func(w http.ResponseWriter, _ *http.Request) {
+//		_ = json.NewEncoder(w).Encode(r.Status())
+//	})
+func (r *Runner) Status() []RunnerStatus {
+	r.mux.Lock()
+	s := r.current
+	if s == nil {
+		statuses := make([]RunnerStatus, 0, len(r.pending))
+		for idx, c := range startOrder(r.pending) {
+			statuses = append(statuses, RunnerStatus{Index: idx, Name: c.name})
+		}
+		r.mux.Unlock()
+		return statuses
+	}
+	r.mux.Unlock()
+	return s.opts.statuses.snapshot()
+}
+
+// statusTracker keeps track of the state of a session's runners, which may
+// be started and shut down concurrently, and reports how many of them are
+// running to the Metrics.
+type statusTracker struct {
+	mux      sync.Mutex
+	statuses map[int]RunnerStatus
+	// running is how many of the runners have started and not yet been
+	// shut down.
+	running int
+	metrics Metrics
+}
+
+func newStatusTracker(metrics Metrics) *statusTracker {
+	return &statusTracker{statuses: make(map[int]RunnerStatus), metrics: metrics}
+}
+
+// started records that the runner with the index has started, replacing
+// anything recorded for an earlier runner in the same place.
+func (t *statusTracker) started(idx int, name string, at time.Time) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	// a replacement takes the place of a runner which is still counted as
+	// running
+	if previous, ok := t.statuses[idx]; !ok || previous.ShutDown {
+		t.running++
+		// set while locked, so that the gauge can't go back to an old count
+		t.metrics.SetRunningRunners(t.running)
+	}
+	t.statuses[idx] = RunnerStatus{Index: idx, Name: name, StartedAt: at}
+}
+
+func (t *statusTracker) shuttingDown(idx int) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	status := t.statuses[idx]
+	status.ShuttingDown = true
+	t.statuses[idx] = status
+}
+
+func (t *statusTracker) shutDown(idx int, duration time.Duration) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	status := t.statuses[idx]
+	if !status.ShutDown {
+		t.running--
+		t.metrics.SetRunningRunners(t.running)
+	}
+	status.ShutDown = true
+	status.ShutdownDuration = duration
+	t.statuses[idx] = status
+}
+
+func (t *statusTracker) snapshot() []RunnerStatus {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	statuses := make([]RunnerStatus, 0, len(t.statuses))
+	for _, status := range t.statuses {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Index < statuses[j].Index
+	})
+	return statuses
+}
@@ -0,0 +1,63 @@
+package rununtil_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRunner_Status(t *testing.T) {
+	clock := newFakeClock()
+	release := make(chan struct{})
+	shuttingDown := make(chan struct{})
+	r := rununtil.New(rununtil.WithClock(clock))
+	if err := r.AddNamed("db", nil, func() rununtil.ShutdownFunc {
+		return func() {
+			close(shuttingDown)
+			<-release
+			clock.advance(time.Second)
+		}
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if statuses := r.Status(); len(statuses) != 1 || statuses[0].Name != "db" || !statuses[0].StartedAt.IsZero() {
+		t.Fatalf("expected the added runner not to have started, got: %+v", statuses)
+	}
+
+	errChan := helperAwaitStarted(t, r)
+	// the added runner is started after the await's own runner
+	var statuses []rununtil.RunnerStatus
+	if !helperWaitFor(func() bool {
+		statuses = r.Status()
+		return len(statuses) == 2
+	}) {
+		t.Fatalf("expected the status of both runners, got: %+v", statuses)
+	}
+	for idx, status := range statuses {
+		if status.Index != idx || status.StartedAt.IsZero() || status.ShuttingDown {
+			t.Fatalf("expected runner %d to be running, got: %+v", idx, status)
+		}
+	}
+	if statuses[1].Name != "db" {
+		t.Fatalf("expected the added runner to be named, got: %+v", statuses[1])
+	}
+
+	r.Cancel()
+	<-shuttingDown
+	statuses = r.Status()
+	if !statuses[1].ShuttingDown || statuses[1].ShutDown {
+		t.Fatalf("expected the added runner to be shutting down, got: %+v", statuses[1])
+	}
+	close(release)
+	if err := <-errChan; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	statuses = r.Status()
+	if !statuses[1].ShutDown || statuses[1].ShutdownDuration != time.Second {
+		t.Fatalf("expected the added runner to have shut down in a second, got: %+v", statuses[1])
+	}
+	if !statuses[0].ShutDown {
+		t.Fatalf("expected the other runner to have shut down, got: %+v", statuses[0])
+	}
+}
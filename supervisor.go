@@ -0,0 +1,92 @@
+package rununtil
+
+import (
+	"context"
+	"time"
+)
+
+// BackoffPolicy controls how SupervisedRunner restarts a worker that has
+// failed.
+type BackoffPolicy struct {
+	// Initial is how long to wait before the first restart.
+	Initial time.Duration
+	// Max caps how long to wait between restarts, where zero means there is
+	// no cap.
+	Max time.Duration
+	// Multiplier is what the wait is multiplied by after each restart. A
+	// multiplier of one or less means always wait for Initial.
+	Multiplier float64
+	// MaxRetries is how many times the worker is restarted before giving up
+	// on it, where zero means keep restarting it forever.
+	MaxRetries int
+	// OnGiveUp is called with the last error once the worker has failed
+	// more than MaxRetries times. By default the error is only logged, with
+	// the Logger set by SetLogger, since the worker can't tell which await
+	// is running it. To shut down gracefully rather than carrying on without
+	// the worker, cancel the await which runs it, e.g. with the Cancel of its
+	// Runner.
+	OnGiveUp func(err error)
+}
+
+// next returns how long to wait before the restart after the one that waited
+// for delay.
+func (b BackoffPolicy) next(delay time.Duration) time.Duration {
+	if b.Multiplier > 1 {
+		delay = time.Duration(float64(delay) * b.Multiplier)
+	}
+	if b.Max > 0 && delay > b.Max {
+		delay = b.Max
+	}
+	return delay
+}
+
+// SupervisedRunner returns a RunnerFunc which runs run in a go routine,
+// restarting it according to the backoff policy whenever it returns an error.
+// Its ShutdownFunc cancels the context given to run and waits for it to
+// return. Once run returns nil, or its context has been cancelled, it is not
+// restarted.
+func SupervisedRunner(run func(ctx context.Context) error, backoff BackoffPolicy) RunnerFunc {
+	if backoff.OnGiveUp == nil {
+		backoff.OnGiveUp = func(err error) {
+			getDefaultLogger().Errorf("giving up on the supervised worker: %v", err)
+		}
+	}
+	return RunnerFunc(func() ShutdownFunc {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			supervise(ctx, run, backoff)
+		}()
+
+		return ShutdownFunc(func() {
+			cancel()
+			<-done
+		})
+	})
+}
+
+// supervise runs run until it succeeds, the context is cancelled or it has
+// been retried too many times.
+func supervise(ctx context.Context, run func(ctx context.Context) error, backoff BackoffPolicy) {
+	delay := backoff.Initial
+	for retries := 0; ; retries++ {
+		err := run(ctx)
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+		if backoff.MaxRetries > 0 && retries >= backoff.MaxRetries {
+			backoff.OnGiveUp(err)
+			return
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		delay = backoff.next(delay)
+	}
+}
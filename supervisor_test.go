@@ -0,0 +1,103 @@
+package rununtil_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRununtilSupervisedRunner_Restarts(t *testing.T) {
+	var calls atomic.Int32
+	running := make(chan struct{})
+	stopped := make(chan struct{})
+	run := func(ctx context.Context) error {
+		if calls.Add(1) < 3 {
+			return errors.New("connection lost")
+		}
+		close(running)
+		<-ctx.Done()
+		close(stopped)
+		return ctx.Err()
+	}
+	backoff := rununtil.BackoffPolicy{Initial: time.Millisecond, Max: 2 * time.Millisecond, Multiplier: 2}
+
+	shutdown := rununtil.SupervisedRunner(run, backoff)()
+	<-running
+	shutdown()
+
+	select {
+	case <-stopped:
+	default:
+		t.Fatal("expected the shutdown function to have waited for the worker to stop")
+	}
+	if n := calls.Load(); n != 3 {
+		t.Fatalf("expected the worker to have been run 3 times, got: %d", n)
+	}
+}
+
+func TestRununtilSupervisedRunner_Succeeds(t *testing.T) {
+	var calls atomic.Int32
+	run := func(ctx context.Context) error {
+		calls.Add(1)
+		return nil
+	}
+
+	shutdown := rununtil.SupervisedRunner(run, rununtil.BackoffPolicy{})()
+	shutdown()
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected a worker that succeeded not to be restarted, got %d runs", n)
+	}
+}
+
+func TestRununtilSupervisedRunner_GivesUp(t *testing.T) {
+	var calls atomic.Int32
+	failure := errors.New("connection refused")
+	run := func(ctx context.Context) error {
+		calls.Add(1)
+		return failure
+	}
+	gaveUp := make(chan error, 1)
+	backoff := rununtil.BackoffPolicy{
+		Initial:    time.Millisecond,
+		MaxRetries: 2,
+		OnGiveUp:   func(err error) { gaveUp <- err },
+	}
+
+	shutdown := rununtil.SupervisedRunner(run, backoff)()
+	if err := <-gaveUp; !errors.Is(err, failure) {
+		t.Fatalf("expected to have given up with %v, got: %v", failure, err)
+	}
+	shutdown()
+	if n := calls.Load(); n != 3 {
+		t.Fatalf("expected the worker to have been run 3 times, got: %d", n)
+	}
+}
+
+func TestRununtilSupervisedRunner_GivesUpWithLog(t *testing.T) {
+	logger := &fakeLogger{}
+	rununtil.SetLogger(logger)
+	defer rununtil.SetLogger(nil)
+	run := func(ctx context.Context) error {
+		return errors.New("connection refused")
+	}
+	backoff := rununtil.BackoffPolicy{Initial: time.Millisecond, MaxRetries: 1}
+
+	done := make(chan struct{})
+	go func() {
+		rununtil.AwaitKillSignals([]os.Signal{syscall.SIGINT}, rununtil.SupervisedRunner(run, backoff))
+		close(done)
+	}()
+	if !helperWaitFor(func() bool { return logger.contains("giving up on the supervised worker: connection refused") }) {
+		t.Fatalf("expected giving up to have been logged, got: %v", logger.lines)
+	}
+	if rununtil.ShuttingDown() {
+		t.Fatal("expected giving up on the worker not to have cancelled the await")
+	}
+	helperCancelUntilDone(t, done)
+}
@@ -0,0 +1,23 @@
+package rununtil
+
+// WithSystemdNotify lets the service take part in systemd's lifecycle when it
+// is run with Type=notify. It sends READY=1 to systemd once all of the runners
+// have started, and any WithReadinessProbe has passed, and STOPPING=1 as soon
+// as shutdown begins, before the WithPreShutdown hooks and the
+// WithLameDuckDelay. It does nothing if the NOTIFY_SOCKET environment variable
+// is not set, or on platforms other than Linux. Any errors are logged rather
+// than returned, since they must not stop the service from running.
+func WithSystemdNotify() Option {
+	return func(o *options) {
+		o.onStarted = append(o.onStarted, func(log Logger) {
+			if err := sdNotify("READY=1"); err != nil {
+				log.Errorf("failed to notify systemd that the service is ready: %v", err)
+			}
+		})
+		o.onStopping = append(o.onStopping, func(log Logger) {
+			if err := sdNotify("STOPPING=1"); err != nil {
+				log.Errorf("failed to notify systemd that the service is stopping: %v", err)
+			}
+		})
+	}
+}
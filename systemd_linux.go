@@ -0,0 +1,28 @@
+package rununtil
+
+import (
+	"fmt"
+	"net"
+	"os"
+)
+
+// sdNotify sends the state to systemd over the unix datagram socket named by
+// the NOTIFY_SOCKET environment variable, as described in sd_notify(3). It does
+// nothing if NOTIFY_SOCKET is not set.
+func sdNotify(state string) error {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return fmt.Errorf("dialling %s: %w", socket, err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return fmt.Errorf("writing %q to %s: %w", state, socket, err)
+	}
+	return nil
+}
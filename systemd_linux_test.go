@@ -0,0 +1,147 @@
+package rununtil_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func helperReadNotification(t *testing.T, conn *net.UnixConn) string {
+	t.Helper()
+	if err := conn.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	buf := make([]byte, 64)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("expected a notification, got error: %v", err)
+	}
+	return string(buf[:n])
+}
+
+func TestRununtilWithSystemdNotify(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer conn.Close()
+	t.Setenv("NOTIFY_SOCKET", socket)
+
+	var hasBeenShutdown atomic.Bool
+	r := rununtil.New(rununtil.WithSystemdNotify())
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(helperMakeFakeRunner(&hasBeenShutdown))
+	}()
+
+	if state := helperReadNotification(t, conn); state != "READY=1" {
+		t.Fatalf("expected READY=1, got: %s", state)
+	}
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state := helperReadNotification(t, conn); state != "STOPPING=1" {
+		t.Fatalf("expected STOPPING=1, got: %s", state)
+	}
+}
+
+func TestRununtilWithSystemdNotify_BeforeLameDuckDelay(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer conn.Close()
+	t.Setenv("NOTIFY_SOCKET", socket)
+
+	var hasBeenShutdown atomic.Bool
+	clock := newFakeClock()
+	r := rununtil.New(rununtil.WithSystemdNotify(), rununtil.WithClock(clock), rununtil.WithLameDuckDelay(time.Hour))
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(helperMakeFakeRunner(&hasBeenShutdown))
+	}()
+
+	if state := helperReadNotification(t, conn); state != "READY=1" {
+		t.Fatalf("expected READY=1, got: %s", state)
+	}
+	helperKeepCancelling(t, r.Cancel, clock.waiting)
+	if state := helperReadNotification(t, conn); state != "STOPPING=1" {
+		t.Fatalf("expected STOPPING=1, got: %s", state)
+	}
+	if hasBeenShutdown.Load() {
+		t.Fatal("expected STOPPING=1 to have been sent during the lame duck delay")
+	}
+	r.ForceNow()
+	if err := <-errChan; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRununtilWithSystemdNotify_ReadinessProbe(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer conn.Close()
+	t.Setenv("NOTIFY_SOCKET", socket)
+
+	clock := newFakeClock()
+	var probes atomic.Int64
+	probe := func(context.Context) error {
+		if probes.Add(1) == 1 {
+			return errors.New("database unreachable")
+		}
+		return nil
+	}
+	r := rununtil.New(rununtil.WithSystemdNotify(), rununtil.WithClock(clock), rununtil.WithReadinessProbe(probe, time.Second))
+
+	var hasBeenShutdown atomic.Bool
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(helperMakeFakeRunner(&hasBeenShutdown))
+	}()
+	// the probe has failed, so nothing can have been sent yet
+	<-clock.waiting
+	if err := conn.SetReadDeadline(time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n, err := conn.Read(make([]byte, 64)); err == nil {
+		t.Fatalf("expected nothing to be sent before the probe passed, got %d bytes", n)
+	}
+
+	clock.advance(time.Second)
+	if state := helperReadNotification(t, conn); state != "READY=1" {
+		t.Fatalf("expected READY=1, got: %s", state)
+	}
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRununtilWithSystemdNotify_NoSocket(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	logger := &fakeLogger{}
+	r := rununtil.New(rununtil.WithSystemdNotify(), rununtil.WithLogger(logger))
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await()
+	}()
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if logger.contains("ERROR") {
+		t.Fatalf("expected nothing to go wrong without a socket, got: %v", logger.lines)
+	}
+}
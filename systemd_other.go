@@ -0,0 +1,8 @@
+//go:build !linux
+
+package rununtil
+
+// sdNotify does nothing, since systemd is only available on Linux.
+func sdNotify(state string) error {
+	return nil
+}
@@ -0,0 +1,22 @@
+package rununtil
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+// ErrShutdownTimeout is returned when a shutdown function did not complete
+// within its timeout.
+var ErrShutdownTimeout = errors.New("shutdown timed out")
+
+// AwaitKillSignalsWithTimeout runs the provided RunnerFuncs until the specified
+// signals have been received, at which point it executes the graceful shutdown
+// functions. Each shutdown function is run in its own go routine and is given
+// the timeout to complete; if it does not, AwaitKillSignalsWithTimeout stops
+// waiting for it, moves on to the next one and eventually returns an error
+// wrapping ErrShutdownTimeout. A timeout of zero means wait forever, which is
+// the same as AwaitKillSignals.
+func AwaitKillSignalsWithTimeout(timeout time.Duration, signals []os.Signal, runnerFuncs ...RunnerFunc) error {
+	return AwaitKillSignalsWithOptions(signals, []Option{WithShutdownTimeout(timeout)}, runnerFuncs...)
+}
@@ -0,0 +1,65 @@
+package rununtil_test
+
+import (
+	"errors"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func helperMakeSlowRunner(delay time.Duration, hasBeenShutdown *atomic.Bool) rununtil.RunnerFunc {
+	return rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		return rununtil.ShutdownFunc(func() {
+			time.Sleep(delay)
+			hasBeenShutdown.Store(true)
+		})
+	})
+}
+
+func TestRununtilAwaitKillSignalsWithTimeout(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	hang := make(chan struct{})
+	defer close(hang)
+	hangingRunner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		return func() { <-hang }
+	})
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- rununtil.AwaitKillSignalsWithTimeout(
+			10*time.Millisecond,
+			[]os.Signal{syscall.SIGINT},
+			helperMakeFakeRunner(&hasBeenShutdown),
+			hangingRunner,
+		)
+	}()
+	if err := helperCancelUntilDone(t, errChan); !errors.Is(err, rununtil.ErrShutdownTimeout) {
+		t.Fatalf("expected a shutdown timeout error, got: %v", err)
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the other shutdown function to have been called")
+	}
+}
+
+func TestRununtilAwaitKillSignalsWithTimeout_Zero(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- rununtil.AwaitKillSignalsWithTimeout(
+			0,
+			[]os.Signal{syscall.SIGINT},
+			helperMakeSlowRunner(20*time.Millisecond, &hasBeenShutdown),
+		)
+	}()
+	if err := helperCancelUntilDone(t, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the shutdown function to have been waited for")
+	}
+}
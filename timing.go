@@ -0,0 +1,29 @@
+package rununtil
+
+import (
+	"sync"
+	"time"
+)
+
+// WithShutdownTiming calls timing as each of the shutdown functions completes,
+// or times out, with the description of its runner, e.g. "runner 2 (http)",
+// how long it took and the error it returned, if any. It is a lightweight
+// alternative to the ShutdownReport for logging each step:
+//
+//	rununtil.WithShutdownTiming(func(name string, d time.Duration, err error) {
+//		log.Printf("%s shut down in %v: %v", name, d, err)
+//	})
+//
+// In a sequential shutdown it is called in the order that the runners are shut
+// down, and in an OrderParallel one in the order that they complete. It is
+// never called by more than one go routine at a time.
+func WithShutdownTiming(timing func(name string, d time.Duration, err error)) Option {
+	var mux sync.Mutex
+	return func(o *options) {
+		o.shutdownTiming = func(name string, d time.Duration, err error) {
+			mux.Lock()
+			defer mux.Unlock()
+			timing(name, d, err)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package rununtil_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+type timingCall struct {
+	name string
+	d    time.Duration
+	err  error
+}
+
+// timingRecorder records all of the calls of a WithShutdownTiming function.
+type timingRecorder struct {
+	mux   sync.Mutex
+	calls []timingCall
+}
+
+func (rec *timingRecorder) timing(name string, d time.Duration, err error) {
+	rec.mux.Lock()
+	defer rec.mux.Unlock()
+	rec.calls = append(rec.calls, timingCall{name: name, d: d, err: err})
+}
+
+func (rec *timingRecorder) names() []string {
+	rec.mux.Lock()
+	defer rec.mux.Unlock()
+	names := make([]string, 0, len(rec.calls))
+	for _, call := range rec.calls {
+		names = append(names, call.name)
+	}
+	return names
+}
+
+func TestRununtilWithShutdownTiming(t *testing.T) {
+	rec := &timingRecorder{}
+	helperAwaitNamed(t, rununtil.New(rununtil.WithShutdownTiming(rec.timing)))
+
+	// called in the order that the runners are shut down, which is reverse
+	expected := []string{"runner 2 (http)", "runner 1 (cache)", "runner 0 (db)"}
+	if names := rec.names(); !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected the timings of %v, got: %v", expected, names)
+	}
+	for _, call := range rec.calls {
+		if call.err != nil || call.d < 0 {
+			t.Fatalf("expected a successful shutdown, got: %+v", call)
+		}
+	}
+}
+
+func TestRununtilWithShutdownTiming_Concurrent(t *testing.T) {
+	rec := &timingRecorder{}
+	fastTimed := make(chan struct{})
+	timing := func(name string, d time.Duration, err error) {
+		rec.timing(name, d, err)
+		if strings.Contains(name, "fast") {
+			close(fastTimed)
+		}
+	}
+	r := rununtil.New(rununtil.WithShutdownTiming(timing), rununtil.WithConcurrentShutdown())
+	// the slow runner is added last, so a sequential shutdown would time it
+	// first
+	if err := r.AddNamed("fast", nil, func() rununtil.ShutdownFunc { return func() {} }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.AddNamed("slow", nil, func() rununtil.ShutdownFunc {
+		return func() { <-fastTimed }
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await()
+	}()
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// called in the order that the runners complete
+	expected := []string{"runner 0 (fast)", "runner 1 (slow)"}
+	if names := rec.names(); !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected the timings of %v, got: %v", expected, names)
+	}
+}
+
+func TestRununtilWithShutdownTiming_Timeout(t *testing.T) {
+	rec := &timingRecorder{}
+	timeout := 10 * time.Millisecond
+	hang := make(chan struct{})
+	defer close(hang)
+	r := rununtil.New(rununtil.WithShutdownTiming(rec.timing), rununtil.WithShutdownTimeout(timeout))
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(func() rununtil.ShutdownFunc {
+			return func() { <-hang }
+		})
+	}()
+	if err := helperKeepCancelling(t, r.Cancel, errChan); !errors.Is(err, rununtil.ErrShutdownTimeout) {
+		t.Fatalf("expected a shutdown timeout, got: %v", err)
+	}
+	if len(rec.calls) != 1 || !errors.Is(rec.calls[0].err, rununtil.ErrShutdownTimeout) || rec.calls[0].d < timeout {
+		t.Fatalf("expected the timeout to have been timed, got: %+v", rec.calls)
+	}
+}
@@ -0,0 +1,52 @@
+package rununtil
+
+// triggeredReason is the reason that the awaits stopped by TriggerShutdown
+// are cancelled with.
+const triggeredReason = "shutdown triggered"
+
+// TriggerShutdown begins the graceful shutdown of all of the Runner's awaits,
+// in the same way as Cancel, and returns straight away. Unlike Cancel it lasts:
+// an await which begins afterwards is shut down as soon as its runners have
+// started, so a test doesn't have to wait for an await that is still getting
+// going before it can stop it. Together with Done it gives a test a
+// deterministic shutdown, which doesn't affect any other Runner:
+//
+//	r.AwaitInBackground(serverRunner)
+//	... exercise the server ...
+//	r.TriggerShutdown()
+//	<-r.Done()
+func (r *Runner) TriggerShutdown() {
+	r.mux.Lock()
+	r.triggered = true
+	r.closeDoneIfFinished()
+	r.mux.Unlock()
+	r.CancelReason(triggeredReason)
+}
+
+// Done returns a channel which is closed once TriggerShutdown has been called
+// and all of the awaits which had begun by then have finished shutting down.
+func (r *Runner) Done() <-chan struct{} {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return r.doneChan()
+}
+
+// doneChan returns the channel which Done returns, making it if need be. It
+// must be called with the mux locked.
+func (r *Runner) doneChan() chan struct{} {
+	if r.done == nil {
+		r.done = make(chan struct{})
+	}
+	return r.done
+}
+
+// closeDoneIfFinished closes the channel which Done returns if the shutdown
+// has been triggered and none of the awaits are still shutting down. It must
+// be called with the mux locked.
+func (r *Runner) closeDoneIfFinished() {
+	if !r.triggered || r.doneClosed || len(r.sessions) > 0 {
+		return
+	}
+	close(r.doneChan())
+	r.doneClosed = true
+}
@@ -0,0 +1,67 @@
+package rununtil_test
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRunner_TriggerShutdown(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	logger := &fakeLogger{}
+	r := rununtil.New(rununtil.WithLogger(logger))
+	r.AwaitInBackground(helperMakeFakeRunner(&hasBeenShutdown))
+
+	select {
+	case <-r.Done():
+		t.Fatal("expected Done to stay open until shutdown has been triggered")
+	default:
+	}
+	r.TriggerShutdown()
+	<-r.Done()
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the shutdown function to have been called")
+	}
+	if !logger.contains("INFO: await cancelled: shutdown triggered") {
+		t.Fatalf("expected the trigger to have been logged, got: %v", logger.lines)
+	}
+}
+
+func TestRunner_TriggerShutdown_BeforeAwait(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	r := rununtil.New()
+	r.TriggerShutdown()
+	<-r.Done()
+
+	// the await is shut down as soon as it has started, without needing to
+	// be cancelled again
+	if err := r.Await(helperMakeFakeRunner(&hasBeenShutdown)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the shutdown function to have been called")
+	}
+}
+
+func TestRunner_TriggerShutdown_Independent(t *testing.T) {
+	var hasBeenShutdown, otherShutdown atomic.Bool
+	r := rununtil.New()
+	other := rununtil.New()
+	r.AwaitInBackground(helperMakeFakeRunner(&hasBeenShutdown))
+	other.AwaitInBackground(helperMakeFakeRunner(&otherShutdown))
+
+	r.TriggerShutdown()
+	<-r.Done()
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the shutdown function to have been called")
+	}
+	if otherShutdown.Load() || other.NumAwaiting() != 1 {
+		t.Fatal("expected the other Runner to still be running")
+	}
+	other.TriggerShutdown()
+	<-other.Done()
+	if !otherShutdown.Load() {
+		t.Fatal("expected the other Runner's shutdown function to have been called")
+	}
+}
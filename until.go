@@ -0,0 +1,65 @@
+package rununtil
+
+import (
+	"context"
+	"os"
+)
+
+// ReasonKind is the kind of thing which stopped an AwaitUntil.
+type ReasonKind int
+
+const (
+	// ReasonSignal means that one of the kill signals was received.
+	ReasonSignal ReasonKind = iota + 1
+	// ReasonContext means that the context was done.
+	ReasonContext
+	// ReasonCancelAll means that the await was cancelled, by CancelAll or
+	// CancelAllReason.
+	ReasonCancelAll
+	// ReasonMaxLifetime means that the WithMaxLifetime had passed.
+	ReasonMaxLifetime
+	// ReasonDone means that the done channel of AwaitKillSignalsWithDone was
+	// closed.
+	ReasonDone
+)
+
+// TerminationReason says why an AwaitUntil stopped.
+type TerminationReason struct {
+	// Kind is what stopped the await.
+	Kind ReasonKind
+	// Signal is the kill signal which was received, for ReasonSignal.
+	Signal os.Signal
+	// Err is what the await failed with, if anything, e.g. a PanicError if a
+	// runner panicked. It is only ever set for RunMain, since AwaitUntil
+	// re-panics instead, and for a ReasonShutdownFunc when one of the runners
+	// failed to start.
+	Err error
+}
+
+// AwaitUntil runs the provided RunnerFuncs until whichever comes first of the
+// specified signals being received, the context being done or CancelAll being
+// called, at which point it executes the graceful shutdown functions. It
+// returns which of them it was:
+//
+//	reason := rununtil.AwaitUntil(ctx, signals, NewRunner(logger))
+//	if reason.Kind == rununtil.ReasonSignal {
+//		log.Printf("stopped by %v", reason.Signal)
+//	}
+//
+// If a RunnerFunc panics it re-panics with a PanicError, in the same way as
+// AwaitKillSignals.
+func AwaitUntil(ctx context.Context, signals []os.Signal, runnerFuncs ...RunnerFunc) TerminationReason {
+	reason := awaitUntil(ctx, signals, starters(runnerFuncs), newOptions(nil))
+	repanic(reason.Err)
+	return reason
+}
+
+// awaitUntil runs the starters until the signals have been received, the
+// context is done or the await has been cancelled, and returns which of them
+// it was along with any error.
+func awaitUntil(ctx context.Context, signals []os.Signal, starters []component, opts options) TerminationReason {
+	opts.ctx = ctx
+	s := defaultRunner.newSession(signals, opts)
+	err := s.run(starters)
+	return TerminationReason{Kind: s.stoppedBy, Signal: s.received, Err: err}
+}
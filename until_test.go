@@ -0,0 +1,63 @@
+package rununtil_test
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRununtilAwaitUntil_Signal(t *testing.T) {
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("unexpected error occurred: %v", err)
+	}
+	var sentSignal, hasBeenShutdown atomic.Bool
+
+	go helperSendSignal(t, p, &sentSignal, syscall.SIGINT, time.Millisecond)
+	reason := rununtil.AwaitUntil(context.Background(), []os.Signal{syscall.SIGINT}, helperMakeFakeRunner(&hasBeenShutdown))
+
+	if reason.Kind != rununtil.ReasonSignal {
+		t.Fatalf("expected the await to have been stopped by a signal, got: %v", reason.Kind)
+	}
+	if reason.Signal != syscall.SIGINT {
+		t.Fatalf("expected the signal to be SIGINT, got: %v", reason.Signal)
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the shutdown function to have been called")
+	}
+}
+
+func TestRununtilAwaitUntil_Context(t *testing.T) {
+	var hasBeenShutdown atomic.Bool
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	reason := rununtil.AwaitUntil(ctx, []os.Signal{syscall.SIGINT}, helperMakeFakeRunner(&hasBeenShutdown))
+
+	if reason.Kind != rununtil.ReasonContext {
+		t.Fatalf("expected the await to have been stopped by the context, got: %v", reason.Kind)
+	}
+	if reason.Signal != nil {
+		t.Fatalf("expected no signal, got: %v", reason.Signal)
+	}
+	if !hasBeenShutdown.Load() {
+		t.Fatal("expected the shutdown function to have been called")
+	}
+}
+
+func TestRununtilAwaitUntil_CancelAll(t *testing.T) {
+	reasons := make(chan rununtil.TerminationReason, 1)
+	go func() {
+		reasons <- rununtil.AwaitUntil(context.Background(), []os.Signal{syscall.SIGINT})
+	}()
+	reason := helperKeepCancelling(t, rununtil.CancelAll, reasons)
+
+	if reason.Kind != rununtil.ReasonCancelAll {
+		t.Fatalf("expected the await to have been cancelled, got: %v", reason.Kind)
+	}
+}
@@ -0,0 +1,79 @@
+package rununtil
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithShutdownWarnAfter logs an error, naming the runners which haven't yet
+// finished shutting down, if the shutdown is still running after d. Unlike the
+// shutdown timeout and deadline it doesn't give up on anything, it is an early
+// warning that shutdown is getting slow before it is slow enough to be cut
+// short. A runner is counted as finished once the await has stopped waiting
+// for it, even if that was because it timed out. A duration of zero, the
+// default, never warns.
+func WithShutdownWarnAfter(d time.Duration) Option {
+	return func(o *options) {
+		o.shutdownWarnAfter = d
+	}
+}
+
+// pendingShutdowns keeps track of which shutdowns haven't finished yet, as
+// they may be executed concurrently.
+type pendingShutdowns struct {
+	mux     sync.Mutex
+	pending map[int]string
+}
+
+func newPendingShutdowns(shutdowns []running) *pendingShutdowns {
+	p := &pendingShutdowns{pending: make(map[int]string, len(shutdowns))}
+	for idx, shutdown := range shutdowns {
+		p.pending[idx] = shutdown.describe(idx)
+	}
+	return p
+}
+
+func (p *pendingShutdowns) finished(idx int) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	delete(p.pending, idx)
+}
+
+// describe lists the shutdowns which haven't finished, in the order of their
+// runners.
+func (p *pendingShutdowns) describe() string {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	idxs := make([]int, 0, len(p.pending))
+	for idx := range p.pending {
+		idxs = append(idxs, idx)
+	}
+	sort.Ints(idxs)
+	names := make([]string, 0, len(idxs))
+	for _, idx := range idxs {
+		names = append(names, p.pending[idx])
+	}
+	return strings.Join(names, ", ")
+}
+
+// warnIfSlow logs the shutdowns which are still pending if the shutdown is
+// still running after the WithShutdownWarnAfter duration, until the returned
+// function is called.
+func warnIfSlow(pending *pendingShutdowns, opts options) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-opts.clock.After(opts.shutdownWarnAfter):
+			opts.logger.Errorf("shutdown still running after %v, waiting for %s", opts.shutdownWarnAfter, pending.describe())
+		case <-done:
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
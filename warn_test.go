@@ -0,0 +1,96 @@
+package rununtil_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaluza-tech/rununtil"
+)
+
+func TestRununtilWithShutdownWarnAfter(t *testing.T) {
+	clock := newFakeClock()
+	logger := &fakeLogger{}
+	release := make(chan struct{})
+	shuttingDown := make(chan struct{})
+	r := rununtil.New(rununtil.WithClock(clock), rununtil.WithLogger(logger), rununtil.WithShutdownWarnAfter(time.Minute))
+
+	errChan := make(chan error)
+	go func() {
+		// the runners are shut down in reverse order, so the last one has
+		// finished by the time the middle one is shutting down
+		errChan <- r.Await(
+			func() rununtil.ShutdownFunc { return func() {} },
+			func() rununtil.ShutdownFunc {
+				return func() {
+					close(shuttingDown)
+					<-release
+				}
+			},
+			func() rununtil.ShutdownFunc { return func() {} },
+		)
+	}()
+	helperKeepCancelling(t, r.Cancel, clock.waiting)
+	<-shuttingDown
+
+	clock.advance(time.Minute)
+	warning := "shutdown still running after 1m0s, waiting for runner 0 (rununtil_test.TestRununtilWithShutdownWarnAfter.func1.1), runner 1 (rununtil_test.TestRununtilWithShutdownWarnAfter.func1.2)"
+	if !helperWaitFor(func() bool { return logger.contains(warning) }) {
+		t.Fatalf("expected the unfinished runners to have been logged, got: %v", logger.lines)
+	}
+	close(release)
+	if err := <-errChan; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRununtilWithShutdownWarnAfter_Concurrent(t *testing.T) {
+	clock := newFakeClock()
+	logger := &fakeLogger{}
+	release := make(chan struct{})
+	hangingRunner := rununtil.RunnerFunc(func() rununtil.ShutdownFunc {
+		return func() { <-release }
+	})
+	r := rununtil.New(
+		rununtil.WithClock(clock),
+		rununtil.WithLogger(logger),
+		rununtil.WithConcurrentShutdown(),
+		rununtil.WithShutdownWarnAfter(time.Minute),
+	)
+	if err := r.AddNamed("db", nil, hangingRunner); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(hangingRunner)
+	}()
+	helperKeepCancelling(t, r.Cancel, clock.waiting)
+
+	clock.advance(time.Minute)
+	warning := "waiting for runner 0 (rununtil_test.TestRununtilWithShutdownWarnAfter_Concurrent.func1), runner 1 (db)"
+	if !helperWaitFor(func() bool { return logger.contains(warning) }) {
+		t.Fatalf("expected all of the unfinished runners to have been logged, got: %v", logger.lines)
+	}
+	close(release)
+	if err := <-errChan; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRununtilWithShutdownWarnAfter_Quick(t *testing.T) {
+	clock := newFakeClock()
+	logger := &fakeLogger{}
+	r := rununtil.New(rununtil.WithClock(clock), rununtil.WithLogger(logger), rununtil.WithShutdownWarnAfter(time.Minute))
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- r.Await(func() rununtil.ShutdownFunc { return func() {} })
+	}()
+	if err := helperKeepCancelling(t, r.Cancel, errChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	clock.advance(time.Minute)
+	if logger.contains("shutdown still running") {
+		t.Fatal("expected no warning once shutdown had completed")
+	}
+}